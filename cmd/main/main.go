@@ -5,11 +5,25 @@ import (
 	"context"
 	tgbot "event-gorganizer/internal/bot"
 	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/server"
 	"event-gorganizer/internal/service"
+	"event-gorganizer/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// chat timezones don't depend on the zoneinfo of the container
+	_ "time/tzdata"
+)
+
+const (
+	defaultShutdownTimeout = 10 * time.Second
+	defaultHttpPort        = "8080"
 )
 
 func main() {
@@ -27,8 +41,15 @@ func main() {
 		}
 	}
 	log.Info().Msg("Starting the bot.")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	shutdownTracing, err := tracing.Init(ctx, viper.GetString("OTEL_TRACES_EXPORTER"), server.Version)
+	if err != nil {
+		log.Error().Msgf("Failed to initialize tracing: %s.", err)
+		os.Exit(3)
+	}
 	gcpSettings := getGcpSettings()
-	eventRepo, err := repository.NewEventRepository(context.Background(), gcpSettings)
+	eventRepo, err := repository.NewEventRepository(ctx, gcpSettings)
 	if err != nil {
 		log.Error().Msgf("Failed to initialize the repository: %s.", err)
 		os.Exit(3)
@@ -40,7 +61,64 @@ func main() {
 		log.Error().Msgf("Failed to initialize the bot: %s.", err)
 		os.Exit(3)
 	}
-	bot.ProcessUpdates()
+	eventService.SetAdminChecker(bot)
+	eventService.SetNotifier(bot)
+	httpServer := startServer(bot, eventRepo)
+	bot.Run(ctx)
+	log.Info().Msg("Stopping the bot.")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), getShutdownTimeout())
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Error().Msgf("Failed to shut down the server: %s.", err)
+	}
+	if err := bot.Shutdown(shutdownCtx); err != nil {
+		log.Error().Msgf("Failed to handle all the updates: %s.", err)
+	}
+	if err := eventRepo.Close(); err != nil {
+		log.Error().Msgf("Failed to close the repository: %s.", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error().Msgf("Failed to flush the traces: %s.", err)
+	}
+	log.Info().Msg("The bot is stopped.")
+}
+
+func startServer(bot *tgbot.TgBot, eventRepo *repository.EventRepository) *http.Server {
+	mux := http.NewServeMux()
+	bot.RegisterHandlers(mux)
+	server.RegisterHealthHandlers(mux, map[string]server.Check{
+		"storage":  eventRepo.Ping,
+		"telegram": bot.CheckTelegram,
+	})
+	mux.Handle("/metrics", promhttp.Handler())
+	httpServer := &http.Server{Addr: ":" + getHttpPort(), Handler: mux}
+	go func() {
+		err := httpServer.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Error().Msgf("Failed to start the server: %s.", err)
+			os.Exit(3)
+		}
+	}()
+	return httpServer
+}
+
+// getHttpPort prefers HTTP_PORT, then PORT set by Cloud Run.
+func getHttpPort() string {
+	if port := viper.GetString("HTTP_PORT"); port != "" {
+		return port
+	}
+	if port := viper.GetString("PORT"); port != "" {
+		return port
+	}
+	return defaultHttpPort
+}
+
+func getShutdownTimeout() time.Duration {
+	if timeout := viper.GetDuration("SHUTDOWN_TIMEOUT"); timeout > 0 {
+		return timeout
+	}
+	return defaultShutdownTimeout
 }
 
 func getGcpSettings() repository.GcpSettings {
@@ -62,8 +140,21 @@ func createBot(eventService *service.EventService) (*tgbot.TgBot, error) {
 	if viper.GetString("ENV") == "LOCAL" {
 		return tgbot.NewPollBot(eventService, tgKey)
 	} else {
-		webhookSecret := viper.GetString("TG_WEBHOOK_SECRET")
-		return tgbot.NewWebhookBot(eventService, webhookSecret, tgKey)
+		return tgbot.NewWebhookBot(eventService, getWebhookSettings(), tgKey)
+	}
+}
+
+func getWebhookSettings() tgbot.WebhookSettings {
+	webhookSecret := viper.GetString("TG_WEBHOOK_SECRET")
+	secretToken := viper.GetString("TG_WEBHOOK_SECRET_TOKEN")
+	if secretToken == "" {
+		secretToken = webhookSecret
+	}
+	return tgbot.WebhookSettings{
+		Url:            viper.GetString("TG_WEBHOOK_URL"),
+		PathSecret:     webhookSecret,
+		SecretToken:    secretToken,
+		MaxConnections: viper.GetInt("TG_WEBHOOK_MAX_CONNECTIONS"),
 	}
 }
 
@@ -0,0 +1,101 @@
+package tgbot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"sync"
+	"time"
+)
+
+const defaultAdminCacheTtl = 10 * time.Minute
+
+// adminCache keeps the administrators of the chats for the ttl. When Telegram can't be reached,
+// the expired entry of the chat is used instead of failing the permission check.
+type adminCache struct {
+	ttl   time.Duration
+	fetch func(ctx context.Context, chatId int64) ([]tgbotapi.ChatMember, error)
+	now   func() time.Time
+
+	mu    sync.Mutex
+	chats map[int64]adminEntry
+}
+
+type adminEntry struct {
+	admins  map[int64]bool
+	fetched time.Time
+}
+
+func newAdminCache(ttl time.Duration, fetch func(ctx context.Context, chatId int64) ([]tgbotapi.ChatMember, error)) *adminCache {
+	if ttl <= 0 {
+		ttl = defaultAdminCacheTtl
+	}
+	return &adminCache{
+		ttl:   ttl,
+		fetch: fetch,
+		now:   time.Now,
+		chats: make(map[int64]adminEntry),
+	}
+}
+
+// isAdmin checks if the user is the creator or an administrator of the chat.
+func (c *adminCache) isAdmin(ctx context.Context, chatId int64, userId int64) (bool, error) {
+	c.mu.Lock()
+	entry, ok := c.chats[chatId]
+	c.mu.Unlock()
+	if ok && c.now().Sub(entry.fetched) < c.ttl {
+		return entry.admins[userId], nil
+	}
+
+	members, err := c.fetch(ctx, chatId)
+	if err != nil {
+		if ok {
+			log.Warn().Msgf("Failed to get administrators of the chat %d, using the ones fetched at %s: %s.",
+				chatId, entry.fetched.Format(time.RFC3339), err)
+			return entry.admins[userId], nil
+		}
+		log.Error().Msgf("Failed to get chat administrators: %s.", err)
+		return false, err
+	}
+	entry = adminEntry{admins: make(map[int64]bool, len(members)), fetched: c.now()}
+	for _, member := range members {
+		if member.IsCreator() || member.IsAdministrator() {
+			entry.admins[member.User.ID] = true
+		}
+	}
+	c.mu.Lock()
+	c.chats[chatId] = entry
+	c.mu.Unlock()
+	return entry.admins[userId], nil
+}
+
+// invalidate drops the administrators of the chat, they are fetched again on the next check.
+func (c *adminCache) invalidate(chatId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.chats, chatId)
+}
+
+// handleMemberUpdate invalidates the cache when a member of the chat, possibly the bot itself, changes status.
+func (c *adminCache) handleMemberUpdate(update tgbotapi.Update) bool {
+	var member *tgbotapi.ChatMemberUpdated
+	if update.ChatMember != nil {
+		member = update.ChatMember
+	} else if update.MyChatMember != nil {
+		member = update.MyChatMember
+	} else {
+		return false
+	}
+	log.Debug().Msgf("Member %d of the chat %d changed status to %s.", member.NewChatMember.User.ID, member.Chat.ID, member.NewChatMember.Status)
+	c.invalidate(member.Chat.ID)
+	return true
+}
+
+// IsAdmin checks if the user is an administrator of the chat, the service authorizes admins with it.
+func (b *TgBot) IsAdmin(ctx context.Context, chatId int64, userId int64) (bool, error) {
+	return b.admins.isAdmin(ctx, chatId, userId)
+}
+
+func (b *TgBot) fetchAdmins(ctx context.Context, chatId int64) ([]tgbotapi.ChatMember, error) {
+	return b.api(ctx).GetChatAdministrators(tgbotapi.ChatAdministratorsConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: chatId}})
+}
@@ -0,0 +1,76 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAdminCache_FetchesOncePerTtl(t *testing.T) {
+	fetches := 0
+	c := newAdminCache(time.Minute, func(ctx context.Context, chatId int64) ([]tgbotapi.ChatMember, error) {
+		fetches++
+		return []tgbotapi.ChatMember{admin(1, "creator"), admin(2, "administrator"), admin(3, "member")}, nil
+	})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	isAdmin, err := c.isAdmin(context.Background(), -100, 1)
+	assert.NoError(t, err)
+	assert.True(t, isAdmin)
+	isAdmin, _ = c.isAdmin(context.Background(), -100, 2)
+	assert.True(t, isAdmin)
+	isAdmin, _ = c.isAdmin(context.Background(), -100, 3)
+	assert.False(t, isAdmin)
+	assert.Equal(t, 1, fetches)
+
+	now = now.Add(2 * time.Minute)
+	_, _ = c.isAdmin(context.Background(), -100, 1)
+	assert.Equal(t, 2, fetches, "Expired entry wasn't fetched again")
+}
+
+func TestAdminCache_FallsBackToStaleEntry(t *testing.T) {
+	var fetchErr error
+	c := newAdminCache(time.Minute, func(ctx context.Context, chatId int64) ([]tgbotapi.ChatMember, error) {
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return []tgbotapi.ChatMember{admin(1, "creator")}, nil
+	})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	_, _ = c.isAdmin(context.Background(), -100, 1)
+
+	now = now.Add(2 * time.Minute)
+	fetchErr = errors.New("Too Many Requests")
+	isAdmin, err := c.isAdmin(context.Background(), -100, 1)
+	assert.NoError(t, err)
+	assert.True(t, isAdmin)
+
+	_, err = c.isAdmin(context.Background(), -200, 1)
+	assert.Error(t, err, "Chat without cached administrators didn't fail")
+}
+
+func TestAdminCache_InvalidatesOnMemberUpdate(t *testing.T) {
+	fetches := 0
+	c := newAdminCache(time.Minute, func(ctx context.Context, chatId int64) ([]tgbotapi.ChatMember, error) {
+		fetches++
+		return []tgbotapi.ChatMember{admin(1, "creator")}, nil
+	})
+	_, _ = c.isAdmin(context.Background(), -100, 1)
+
+	handled := c.handleMemberUpdate(tgbotapi.Update{ChatMember: &tgbotapi.ChatMemberUpdated{
+		Chat:          tgbotapi.Chat{ID: -100},
+		NewChatMember: admin(2, "administrator"),
+	}})
+	assert.True(t, handled)
+	_, _ = c.isAdmin(context.Background(), -100, 2)
+	assert.Equal(t, 2, fetches)
+}
+
+func admin(userId int64, status string) tgbotapi.ChatMember {
+	return tgbotapi.ChatMember{User: &tgbotapi.User{ID: userId}, Status: status}
+}
@@ -0,0 +1,71 @@
+package tgbot
+
+import (
+	"context"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultLogSize is the number of changes /log shows without an argument.
+const defaultLogSize = 20
+
+// auditLog shows the latest changes of the active event with /log, /log 50 shows more of them.
+func (b *TgBot) auditLog(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	hasPermission, err := b.hasPermissionToManageSettings(ctx, update.Message.From, chatId)
+	if err != nil {
+		return l.T("permissions.failed"), metrics.OutcomeError
+	}
+	if !hasPermission {
+		return l.T("log.denied"), metrics.OutcomePermissionDenied
+	}
+	size := defaultLogSize
+	if hasArguments(update.Message) {
+		size, err = strconv.Atoi(strings.TrimSpace(update.Message.CommandArguments()))
+		if err != nil || size <= 0 {
+			return l.T("log.usage"), metrics.OutcomeError
+		}
+	}
+	event, err := b.eventService.GetActiveEvent(ctx, chatId)
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to get an active event for the chat %d: %s.", chatId, err)
+		return l.T("event.get.failed"), metrics.OutcomeError
+	}
+	settings, err := b.eventService.GetSettings(ctx, chatId)
+	if err != nil {
+		log.Error().Msgf("Failed to get settings of the chat %d: %s.", chatId, err)
+		return l.T("log.failed"), metrics.OutcomeError
+	}
+	entries, err := b.eventService.GetAuditLog(ctx, event, size)
+	if err != nil {
+		log.Error().Msgf("Failed to get the audit log of the chat %d: %s.", chatId, err)
+		return l.T("log.failed"), metrics.OutcomeError
+	}
+	if len(entries) == 0 {
+		return l.T("log.empty"), metrics.OutcomeSuccess
+	}
+	return formatAuditLog(l, entries, settings.Location()), metrics.OutcomeSuccess
+}
+
+// formatAuditLog lists the entries, the oldest first, with the times in the location of the chat.
+func formatAuditLog(l i18n.Localizer, entries []*model.AuditEntry, loc *time.Location) string {
+	var sb strings.Builder
+	sb.WriteString(l.T("log.title"))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		sb.WriteString("\n")
+		sb.WriteString(entry.Time.In(loc).Format("02.01 15:04"))
+		sb.WriteString(" ")
+		sb.WriteString(l.T("log.action."+entry.Action, entry.ActorName, entry.Target, entry.Before, entry.After))
+	}
+	return sb.String()
+}
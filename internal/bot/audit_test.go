@@ -0,0 +1,41 @@
+package tgbot
+
+import (
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFormatAuditLog_OldestFirst(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	entries := []*model.AuditEntry{
+		{ActorName: "@bob", Action: model.ActionRename, Target: "#2 Charlie", Before: "Charly", After: "Charlie",
+			Time: time.Date(2024, time.May, 1, 17, 5, 0, 0, time.UTC)},
+		{ActorName: "@alice", Action: model.ActionAdd, Target: "#2 Charly",
+			Time: time.Date(2024, time.May, 1, 16, 0, 0, 0, time.UTC)},
+	}
+
+	text := formatAuditLog(i18n.For("en"), entries, loc)
+
+	assert.Equal(t, "Recent changes:\n01.05 18:00 @alice added #2 Charly\n01.05 19:05 @bob renamed Charly to Charlie", text)
+}
+
+func TestFormatAuditLog_AllActionsLocalized(t *testing.T) {
+	actions := []string{
+		model.ActionCreate, model.ActionClose, model.ActionAdd, model.ActionRemove, model.ActionPaid,
+		model.ActionRename, model.ActionMove, model.ActionSetInviter, model.ActionSetting,
+		model.ActionTemplate, model.ActionResetTemplate, model.ActionGrant, model.ActionRevoke, model.ActionUndo,
+		model.ActionCancel, model.ActionReschedule,
+	}
+	for _, lang := range i18n.Languages() {
+		for _, action := range actions {
+			entry := &model.AuditEntry{ActorName: "@alice", Action: action, Target: "#1 Bob", Before: "a", After: "b"}
+			text := formatAuditLog(i18n.For(lang), []*model.AuditEntry{entry}, time.UTC)
+			assert.NotContainsf(t, text, "log.action", "Missing %s translation of %s", lang, action)
+			assert.NotContainsf(t, text, "%!", "Bad %s format of %s", lang, action)
+		}
+	}
+}
@@ -1,33 +1,47 @@
 package tgbot
 
 import (
-	"bytes"
 	"context"
-	_ "embed"
+	"errors"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
 	"event-gorganizer/internal/model"
 	"event-gorganizer/internal/service"
 	"fmt"
-	"github.com/Masterminds/sprig/v3"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	templating "html/template"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
 )
 
+var tracer = otel.Tracer("event-gorganizer/internal/bot")
+
 type TgBot struct {
-	bot                    *tgbotapi.BotAPI
-	updates                tgbotapi.UpdatesChannel
-	eventService           *service.EventService
-	eventRenderingTemplate *templating.Template
+	bot            *tgbotapi.BotAPI
+	updates        tgbotapi.UpdatesChannel
+	webhook        *WebhookSettings
+	dispatcher     *dispatcher
+	sender         *sender
+	admins         *adminCache
+	seen           seenUsers
+	lastGetMe      atomic.Int64
+	eventService   *service.EventService
+	eventTemplates map[string]*templating.Template
 }
 
 func NewPollBot(eventService *service.EventService, tgKey string) (*TgBot, error) {
 	log.Info().Msg("Starting the bot in poll mode.")
-	bot, err := tgbotapi.NewBotAPI(tgKey)
+	bot, err := newBotAPI(tgKey)
 	if err != nil {
 		log.Error().Msgf("Failed registering the bot: %s.", err)
 		return nil, err
@@ -40,21 +54,22 @@ func NewPollBot(eventService *service.EventService, tgKey string) (*TgBot, error
 	bot.Debug = viper.GetBool("BOT_DEBUG")
 	updateConfig := tgbotapi.NewUpdate(0)
 	updateConfig.Timeout = 30
-	template, err := getTemplate()
+	updateConfig.AllowedUpdates = allowedUpdates
+	tgBot, err := newTgBot(bot, eventService)
 	if err != nil {
 		return nil, err
 	}
-	return &TgBot{
-		bot:                    bot,
-		updates:                bot.GetUpdatesChan(updateConfig),
-		eventService:           eventService,
-		eventRenderingTemplate: template,
-	}, nil
+	tgBot.updates = bot.GetUpdatesChan(updateConfig)
+	return tgBot, nil
 }
 
-func NewWebhookBot(eventService *service.EventService, webhookSecret string, tgKey string) (*TgBot, error) {
+func NewWebhookBot(eventService *service.EventService, settings WebhookSettings, tgKey string) (*TgBot, error) {
 	log.Info().Msg("Starting the bot in webhook mode.")
-	bot, err := tgbotapi.NewBotAPI(tgKey)
+	if err := settings.validate(); err != nil {
+		log.Error().Msgf("Invalid webhook settings: %s.", err)
+		return nil, err
+	}
+	bot, err := newBotAPI(tgKey)
 	if err != nil {
 		log.Error().Msgf("Failed to initialize the bot: %s", err.Error())
 		return nil, err
@@ -62,254 +77,563 @@ func NewWebhookBot(eventService *service.EventService, webhookSecret string, tgK
 
 	bot.Debug = true
 
-	info, err := bot.GetWebhookInfo()
+	tgBot, err := newTgBot(bot, eventService)
 	if err != nil {
-		log.Error().Msg(err.Error())
 		return nil, err
 	}
+	tgBot.webhook = &settings
 
-	if info.LastErrorDate != 0 {
-		log.Error().Msgf("Telegram callback failed: %s.", info.LastErrorMessage)
+	if err := tgBot.registerWebhook(context.Background()); err != nil {
 		return nil, err
 	}
+	return tgBot, nil
+}
 
-	updates := bot.ListenForWebhook("/" + webhookSecret)
-	go func() {
-		err := http.ListenAndServe(":8080", nil)
-		if err != nil {
-			log.Error().Msgf("Failed to start the server: %s.", err)
-			os.Exit(3)
-		}
-	}()
-	template, err := getTemplate()
+func newTgBot(bot *tgbotapi.BotAPI, eventService *service.EventService) (*TgBot, error) {
+	templates, err := getTemplates()
 	if err != nil {
 		return nil, err
 	}
-	return &TgBot{
-		bot:                    bot,
-		updates:                updates,
-		eventService:           eventService,
-		eventRenderingTemplate: template,
-	}, nil
+	tgBot := &TgBot{
+		bot:            bot,
+		eventService:   eventService,
+		eventTemplates: templates,
+		seen:           seenUsers{users: make(map[string]model.ChatUser)},
+	}
+	// the client calls getMe on creation
+	tgBot.lastGetMe.Store(time.Now().UnixNano())
+	tgBot.dispatcher = newDispatcher(viper.GetInt("BOT_WORKERS"), viper.GetInt("BOT_QUEUE_SIZE"), tgBot.handleUpdate)
+	metrics.RegisterQueueDepth(func() float64 { return float64(tgBot.dispatcher.queueDepth()) })
+	tgBot.admins = newAdminCache(viper.GetDuration("ADMIN_CACHE_TTL"), tgBot.fetchAdmins)
+	tgBot.sender = newSender(func(ctx context.Context, msg tgbotapi.Chattable) error {
+		_, err := tgBot.api(ctx).Request(msg)
+		return err
+	})
+	metrics.RegisterOutgoingQueueDepth(func() float64 { return float64(tgBot.sender.queueDepth()) })
+	return tgBot, nil
 }
 
-func (b *TgBot) ProcessUpdates() {
-	for update := range b.updates {
-		ctx := context.Background()
+// RegisterHandlers adds the webhook endpoint to the mux, it's a no-op for a bot in poll mode.
+func (b *TgBot) RegisterHandlers(mux *http.ServeMux) {
+	if b.webhook != nil {
+		mux.Handle(b.webhook.path(), b.webhookHandler())
+	}
+}
 
-		if update.Message == nil { // ignore any non-Message updates
-			continue
+// Run handles updates until the context is cancelled. In poll mode receiving updates stops on return,
+// in webhook mode the updates keep coming until the HTTP server is shut down.
+// Use Shutdown to wait for the updates being handled.
+func (b *TgBot) Run(ctx context.Context) {
+	b.sender.start()
+	b.dispatcher.start()
+	go b.watchTelegram(ctx)
+	go b.announceOpenings(ctx)
+	go b.remindKickoffs(ctx)
+	if b.webhook != nil {
+		go b.watchWebhook(ctx)
+	}
+	if b.updates == nil {
+		<-ctx.Done()
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			b.bot.StopReceivingUpdates()
+			b.drainReceivedUpdates()
+			return
+		case update, ok := <-b.updates:
+			if !ok {
+				return
+			}
+			b.dispatcher.dispatch(update)
 		}
+	}
+}
 
-		if !update.Message.IsCommand() { // ignore any non-command Messages
-			continue
+// Shutdown waits for the dispatched updates to be handled and the replies to be sent. When the context
+// expires first, the handlers and sending are cancelled and the context error is returned.
+func (b *TgBot) Shutdown(ctx context.Context) error {
+	dispatcherErr := b.dispatcher.stop(ctx)
+	senderErr := b.sender.stop(ctx)
+	return errors.Join(dispatcherErr, senderErr)
+}
+
+// drainReceivedUpdates dispatches updates already fetched from Telegram, they won't be delivered again.
+func (b *TgBot) drainReceivedUpdates() {
+	for {
+		select {
+		case update, ok := <-b.updates:
+			if !ok {
+				return
+			}
+			b.dispatcher.dispatch(update)
+		default:
+			return
+		}
+	}
+}
+
+// handleUpdate handles the update for the chat whose event it changes, the group selected in the private chat
+// for the routed commands.
+func (b *TgBot) handleUpdate(ctx context.Context, update tgbotapi.Update, chatId int64) {
+	if b.admins.handleMemberUpdate(update) {
+		return
+	}
+
+	if query := update.CallbackQuery; query != nil {
+		chatId := query.From.ID
+		if query.Message != nil {
+			chatId = query.Message.Chat.ID
 		}
+		b.processOnce(ctx, chatId, update, func(ctx context.Context) string {
+			switch data := query.Data; {
+			case strings.HasPrefix(data, guestsCallbackPrefix):
+				return b.handleGuestsCallback(ctx, update)
+			case strings.HasPrefix(data, privateCallbackPrefix):
+				return b.handlePrivateCallback(ctx, update)
+			case strings.HasPrefix(data, notifyCallbackPrefix):
+				return b.handleNotifyCallback(ctx, update)
+			default:
+				return b.handleSettingsCallback(ctx, update)
+			}
+		})
+		return
+	}
+
+	if update.Message == nil { // ignore any non-Message updates
+		return
+	}
+
+	if !update.Message.IsCommand() { // ignore any non-command Messages
+		return
+	}
+
+	if update.Message.Chat.IsPrivate() && routedCommands[update.Message.Command()] && chatId == update.Message.Chat.ID {
+		b.routeToGroup(ctx, update)
+		return
+	}
+
+	ctx, span := tracer.Start(ctx, "update", trace.WithAttributes(
+		attribute.Int("update.id", update.UpdateID),
+		attribute.Int64("chat.id", update.Message.Chat.ID),
+		attribute.String("command", update.Message.Command()),
+	))
+	defer span.End()
 
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "")
+	b.processOnce(ctx, update.Message.Chat.ID, update, func(ctx context.Context) string {
+		return b.handleCommand(ctx, update, chatId)
+	})
+}
 
-		arguments := update.Message.CommandArguments()
-		chatId := update.FromChat().ID
-		switch update.Message.Command() {
-		case "new":
-			chatId := chatId
-			hasPermission, err := b.hasPermissionToCreateEvent(update.SentFrom().ID, chatId)
+// processOnce handles the update unless it was already handled, the handler returns the outcome. Telegram delivers
+// an update again only if the previous delivery wasn't answered, e.g. the webhook response was lost, and the bot
+// answers once the update is queued, so failed updates aren't delivered again, the user repeats the command.
+// The request key skips the changes of a redelivered update that were applied before it was recorded.
+func (b *TgBot) processOnce(ctx context.Context, chatId int64, update tgbotapi.Update, handle func(context.Context) string) {
+	processed, err := b.eventService.IsUpdateProcessed(ctx, chatId, update.UpdateID)
+	if err != nil {
+		log.Warn().Msgf("Failed to check if the update %d was processed: %s.", update.UpdateID, err)
+	} else if processed {
+		log.Info().Msgf("The update %d was already processed, skipping it.", update.UpdateID)
+		return
+	}
+	ctx = service.WithRequestKey(ctx, fmt.Sprintf("update-%d", update.UpdateID))
+	if handle(ctx) == metrics.OutcomeError {
+		return
+	}
+	if err = b.eventService.MarkUpdateProcessed(ctx, chatId, update.UpdateID); err != nil {
+		log.Warn().Msgf("Failed to record the update %d as processed: %s.", update.UpdateID, err)
+	}
+}
+
+// handleCommand replies to the command of the message changing the chat and returns the outcome.
+func (b *TgBot) handleCommand(ctx context.Context, update tgbotapi.Update, chatId int64) (outcome string) {
+	span := trace.SpanFromContext(ctx)
+	b.recordUser(ctx, update.Message)
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, "")
+
+	arguments := update.Message.CommandArguments()
+	l := b.localizer(ctx, update.Message.Chat.ID, update.Message.From)
+	command := update.Message.Command()
+	outcome = metrics.OutcomeSuccess
+	defer func(start time.Time) {
+		metrics.ObserveCommand(command, outcome, start)
+		span.SetAttributes(attribute.String("outcome", outcome))
+		if outcome == metrics.OutcomeError {
+			span.SetStatus(codes.Error, msg.Text)
+		}
+	}(time.Now())
+	actor := newChatUser(chatId, update.Message.From)
+	switch command {
+	case "start":
+		outcome = b.start(ctx, update, l, &msg)
+	case "new":
+		creator := getSelf(update)
+		_, err := b.eventService.CreateNewEvent(ctx, chatId, creator, arguments, actor)
+		if err != nil {
+			if isDuplicate(update, err) {
+				return
+			}
+			if text, rejected, ok := rejection(l, err); ok {
+				msg.Text, outcome = text, rejected
+				break
+			}
+			log.Error().Msgf("Failed to create an event for the chat %d: %s.", chatId, err)
+			msg.Text = l.T("event.create.failed")
+			outcome = metrics.OutcomeError
+		} else {
+			msg.Text = l.T("event.created")
+		}
+	case "event":
+		event, err := b.eventService.GetActiveEvent(ctx, chatId)
+		if text, rejected, ok := rejection(l, err); ok {
+			msg.Text, outcome = text, rejected
+			break
+		}
+		if err != nil {
+			log.Error().Msgf("Failed to get an active event for the chat %d: %s.", chatId, err)
+			msg.Text = l.T("event.get.failed")
+			outcome = metrics.OutcomeError
+			break
+		}
+		settings, err := b.eventService.GetSettings(ctx, chatId)
+		if err != nil {
+			log.Error().Msgf("Failed to get settings of the chat %d: %s.", chatId, err)
+			msg.Text = l.T("event.get.failed")
+			outcome = metrics.OutcomeError
+		} else {
+			msg.ParseMode = tgbotapi.ModeHTML
+			msg.Text = b.renderEvent(ctx, chatId, l, NewEventView(event, settings, l))
+			if keyboard, ok := b.privateChatKeyboard(l, chatId); ok && !update.Message.Chat.IsPrivate() {
+				msg.ReplyMarkup = keyboard
+			}
+		}
+	case "i":
+		self := getSelf(update)
+		if addsMember(update.Message) {
+			member, text, failure := b.memberToAdd(ctx, chatId, update, self, l)
+			if member == nil {
+				msg.Text, outcome = text, failure
+				break
+			}
+			added, err := b.eventService.AddNewParticipant(ctx, chatId, member, actor)
 			if err != nil {
-				log.Error().Msgf("Failed to check permissions for the chat %d: %s.", chatId, err)
-				msg.Text = "Failed to check permissions."
-			} else if hasPermission {
-				creator := getSelf(update)
-				_, err := b.eventService.CreateNewEvent(ctx, chatId, creator, arguments)
-				if err != nil {
-					log.Error().Msgf("Failed to create an event for the chat %d: %s.", chatId, err)
-					msg.Text = "Failed to create an event."
-				} else {
-					msg.Text = "Event created."
+				if isDuplicate(update, err) {
+					return
+				}
+				if text, rejected, ok := rejection(l, err); ok {
+					msg.Text, outcome = text, rejected
+					break
+				}
+				if errors.Is(err, service.ErrAlreadyRegistered) {
+					msg.Text = l.T("participant.already.registered", member.Name)
+					break
 				}
+				log.Error().Msgf("Failed to add %s: %s.", member.Name, err)
+				msg.Text = l.T("participant.add.failed", member.Name)
+				outcome = metrics.OutcomeError
+			} else if added.InvitedBy != nil {
+				msg.Text = l.T("participant.added.by", added.Name, self.Name)
 			} else {
-				msg.Text = "Event wasn't created, not enough rights."
+				msg.Text = l.T("participant.added", added.Name)
 			}
-		case "event":
-			event, err := b.eventService.GetActiveEvent(ctx, chatId)
+		} else if hasArguments(update.Message) {
+			invitedPerson := arguments
+			invitedParticipant := &model.Participant{
+				Name:       invitedPerson,
+				TelegramId: nil,
+				InvitedBy:  self,
+			}
+			invitedParticipant, err := b.eventService.AddNewParticipant(ctx, chatId, invitedParticipant, actor)
 			if err != nil {
-				log.Error().Msgf("Failed to get an active event for the chat %d: %s.", chatId, err)
-				msg.Text = "Failed to get an active event."
+				if isDuplicate(update, err) {
+					return
+				}
+				if text, rejected, ok := rejection(l, err); ok {
+					msg.Text, outcome = text, rejected
+					break
+				}
+				if errors.Is(err, service.ErrAlreadyRegistered) {
+					msg.Text = l.T("participant.already.registered", invitedPerson)
+					break
+				}
+				log.Error().Msgf("Failed to add %s: %s.", invitedPerson, err)
+				msg.Text = l.T("participant.add.failed", invitedPerson)
+				outcome = metrics.OutcomeError
 			} else {
-				msg.ParseMode = tgbotapi.ModeHTML
-				msg.Text = b.renderEvent(NewEventView(event))
+				msg.Text = l.T("participant.added.by", invitedPerson, self.Name)
 			}
-		case "i":
-			self := getSelf(update)
-			if hasArguments(update.Message) {
-				invitedPerson := arguments
-				invitedParticipant := &model.Participant{
-					Name:       invitedPerson,
-					TelegramId: nil,
-					InvitedBy:  self,
+		} else {
+			_, err := b.eventService.AddNewParticipant(ctx, chatId, self, actor)
+			if err != nil {
+				if isDuplicate(update, err) {
+					return
 				}
-				invitedParticipant, err := b.eventService.AddNewParticipant(ctx, chatId, invitedParticipant)
-				if err != nil {
-					log.Error().Msgf("Failed to add %s: %s.", invitedPerson, err)
-					msg.Text = fmt.Sprintf("Failed to add %s.", invitedPerson)
-				} else {
-					msg.Text = fmt.Sprintf("%s added by %s.", invitedPerson, self.Name)
+				if text, rejected, ok := rejection(l, err); ok {
+					msg.Text, outcome = text, rejected
+					break
 				}
-			} else {
-				_, err := b.eventService.AddNewParticipant(ctx, chatId, self)
-				if err != nil {
-					log.Error().Msgf("Failed to add %s: %s.", self.Name, err)
-					msg.Text = fmt.Sprintf("Failed to add %s.", self.Name)
-				} else {
-					msg.Text = fmt.Sprintf("%s added.", self.Name)
+				if errors.Is(err, service.ErrAlreadyRegistered) {
+					msg.Text = l.T("participant.already.registered", self.Name)
+					break
 				}
+				log.Error().Msgf("Failed to add %s: %s.", self.Name, err)
+				msg.Text = l.T("participant.add.failed", self.Name)
+				outcome = metrics.OutcomeError
+			} else {
+				msg.Text = l.T("participant.added", self.Name)
 			}
-		case "cant":
-			self := getSelf(update)
-			if hasArguments(update.Message) {
-				participantNumber, err := strconv.Atoi(arguments)
-				if err != nil {
-					msg.Text = fmt.Sprintf("Incorrect participant number: %s.", arguments)
+		}
+	case "cant":
+		self := getSelf(update)
+		if hasArguments(update.Message) {
+			participantNumber, err := strconv.Atoi(arguments)
+			if err != nil {
+				msg.Text = l.T("participant.number.invalid", arguments)
+				outcome = metrics.OutcomeError
+				break
+			}
+			removed, err := b.eventService.RemoveParticipantByNumber(ctx, chatId, participantNumber, actor)
+			if err != nil {
+				if isDuplicate(update, err) {
+					return
+				}
+				if text, rejected, ok := rejection(l, err); ok {
+					msg.Text, outcome = text, rejected
 					break
 				}
-				removed, err := b.eventService.RemoveParticipantByNumber(ctx, chatId, participantNumber)
-				if err != nil {
-					log.Error().Msgf("Failed to remove %d: %s.", participantNumber, err)
-					msg.Text = fmt.Sprintf("Failed to remove %d.", participantNumber)
-				} else {
-					msg.Text = fmt.Sprintf("%s won't attend.", removed.Name)
+				if errors.Is(err, service.ErrParticipantNotFound) {
+					msg.Text = l.T("participant.not.found", participantNumber)
+					outcome = metrics.OutcomeError
+					break
 				}
+				log.Error().Msgf("Failed to remove %d: %s.", participantNumber, err)
+				msg.Text = l.T("participant.remove.failed", participantNumber)
+				outcome = metrics.OutcomeError
 			} else {
-				_, err := b.eventService.RemoveParticipant(ctx, chatId, self)
-				if err != nil {
-					log.Error().Msgf("Failed to remove %s: %s.", self.Name, err)
-					msg.Text = fmt.Sprintf("Failed to remove %s.", self.Name)
-				} else {
-					msg.Text = fmt.Sprintf("%s won't attend.", self.Name)
-				}
+				msg.Text = l.T("participant.removed", removed.Name)
 			}
-		case "paid":
-			self := getSelf(update)
-			if hasArguments(update.Message) {
-				participantNumber, err := strconv.Atoi(arguments)
-				if err != nil {
-					msg.Text = fmt.Sprintf("Incorrect participant number: %s.", arguments)
+		} else {
+			_, err := b.eventService.RemoveParticipant(ctx, chatId, self, actor)
+			if err != nil {
+				if isDuplicate(update, err) {
+					return
+				}
+				if text, rejected, ok := rejection(l, err); ok {
+					msg.Text, outcome = text, rejected
 					break
 				}
-				participant, err := b.eventService.FindParticipantByNumber(ctx, chatId, participantNumber)
-				if err != nil {
-					msg.Text = "Failed to mark as paid."
+				if errors.Is(err, service.ErrParticipantNotFound) {
+					msg.Text = l.T("participant.not.registered", self.Name)
 					break
 				}
-				if participant == nil {
-					msg.Text = fmt.Sprintf("A participant with number %d not found.", participantNumber)
+				log.Error().Msgf("Failed to remove %s: %s.", self.Name, err)
+				msg.Text = l.T("participant.remove.failed", self.Name)
+				outcome = metrics.OutcomeError
+			} else {
+				msg.Text = l.T("participant.removed", self.Name)
+				b.promptGuestRemoval(ctx, chatId, self, l, &msg)
+			}
+		}
+	case "paid":
+		self := getSelf(update)
+		if hasArguments(update.Message) {
+			participantNumber, err := strconv.Atoi(arguments)
+			if err != nil {
+				msg.Text = l.T("participant.number.invalid", arguments)
+				outcome = metrics.OutcomeError
+				break
+			}
+			paid, err := b.eventService.MarkPaidByNumber(ctx, chatId, participantNumber, actor)
+			if err != nil {
+				if isDuplicate(update, err) {
+					return
+				}
+				if text, rejected, ok := rejection(l, err); ok {
+					msg.Text, outcome = text, rejected
 					break
 				}
-				hasPermission, err := b.hasPermissionToMarkPaid(*self.TelegramId, chatId, *participant)
-				if err != nil {
-					log.Error().Msgf("Failed to check permissions for the chat %d: %s.", chatId, err)
-					msg.Text = "Failed to check permissions."
+				if errors.Is(err, service.ErrParticipantNotFound) {
+					msg.Text = l.T("participant.not.found", participantNumber)
+					outcome = metrics.OutcomeError
 					break
 				}
-				if !hasPermission {
-					msg.Text = "Not enough rights to mark as paid. "
+				log.Error().Msgf("Failed to mark paid %d: %s.", participantNumber, err)
+				msg.Text = l.T("paid.failed.for", participantNumber)
+				outcome = metrics.OutcomeError
+			} else {
+				msg.Text = l.T("paid.marked", paid.Name)
+			}
+		} else {
+			_, err := b.eventService.MarkPaid(ctx, chatId, self, actor)
+			if err != nil {
+				if isDuplicate(update, err) {
+					return
+				}
+				if text, rejected, ok := rejection(l, err); ok {
+					msg.Text, outcome = text, rejected
 					break
 				}
-				err = b.eventService.MarkPaidByNumber(ctx, chatId, participantNumber)
-				if err != nil {
-					log.Error().Msgf("Failed to mark paid %d: %s.", participantNumber, err)
-					msg.Text = fmt.Sprintf("Failed to mark paid %d.", participantNumber)
-				} else {
-					msg.Text = fmt.Sprintf("%s paid.", participant.Name)
+				if errors.Is(err, service.ErrParticipantNotFound) {
+					msg.Text = l.T("participant.not.registered", self.Name)
+					break
 				}
+				log.Error().Msgf("Failed to mark paid %s: %s.", self.Name, err)
+				msg.Text = l.T("paid.failed.for", self.Name)
+				outcome = metrics.OutcomeError
 			} else {
-				err := b.eventService.MarkPaid(ctx, chatId, self)
-				if err != nil {
-					log.Error().Msgf("Failed to mark paid %s: %s.", self.Name, err)
-					msg.Text = fmt.Sprintf("Failed to mark paid %s.", self.Name)
-				} else {
-					msg.Text = fmt.Sprintf("%s paid.", self.Name)
-				}
+				msg.Text = l.T("paid.marked", self.Name)
 			}
-		default:
-			msg.Text = fmt.Sprintf("Unknown command: %s.", update.Message.Command())
 		}
+	case "settings":
+		outcome = b.settings(ctx, update, l, &msg)
+	case "rename":
+		msg.Text, outcome = b.renameParticipant(ctx, update, l)
+	case "move":
+		msg.Text, outcome = b.moveParticipant(ctx, update, l)
+	case "inviter":
+		msg.Text, outcome = b.changeInviter(ctx, update, l)
+	case "template":
+		outcome = b.eventTemplate(ctx, update, l, &msg)
+	case "grant":
+		msg.Text, outcome = b.grantRole(ctx, update, l)
+	case "revoke":
+		msg.Text, outcome = b.revokeRole(ctx, update, l)
+	case "roles":
+		msg.Text, outcome = b.listRoles(ctx, update, l)
+	case "log":
+		msg.Text, outcome = b.auditLog(ctx, update, l)
+	case "undo":
+		msg.Text, outcome = b.undo(ctx, update, l)
+	case "kickoff", "opens", "close", "reopen", "reschedule":
+		msg.Text, outcome = b.scheduleRegistration(ctx, update, l)
+	case "remind":
+		msg.Text, outcome = b.remindUnpaid(ctx, update, chatId, l)
+	case "cancel":
+		msg.Text, outcome = b.cancelEvent(ctx, update, l)
+	case "history":
+		msg.Text, outcome = b.history(ctx, update, l)
+	default:
+		msg.Text = l.T("command.unknown", update.Message.Command())
+		command = "unknown"
+		outcome = metrics.OutcomeError
+	}
 
-		if _, err := b.bot.Send(msg); err != nil {
-			log.Error().Msgf("Failed to send the message: %s", err)
-		}
+	if msg.Text == "" {
+		// the update was already applied
+		return
 	}
+	b.sender.enqueue(ctx, msg.ChatID, msg)
+	return
 }
 
-func (b *TgBot) hasPermissionToCreateEvent(userId int64, chatId int64) (bool, error) {
-	resp, err := b.bot.GetChatAdministrators(tgbotapi.ChatAdministratorsConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: chatId}})
+// localizer picks the language of the replies to the user in the chat.
+func (b *TgBot) localizer(ctx context.Context, chatId int64, user *tgbotapi.User) i18n.Localizer {
+	settings, err := b.eventService.GetSettings(ctx, chatId)
 	if err != nil {
-		log.Error().Msgf("Failed to get chat administrators: %s.", err)
-		return false, err
+		log.Warn().Msgf("Failed to get the language of the chat %d: %s.", chatId, err)
+		settings = model.DefaultChatSettings(chatId)
 	}
-	for _, member := range resp {
-		if userId == member.User.ID && (member.IsCreator() || member.IsAdministrator()) {
-			return true, nil
-		}
-	}
-	return false, nil
+	return localize(chatId, user, settings)
 }
 
-func (b *TgBot) hasPermissionToMarkPaid(userId int64, chatId int64, participant model.Participant) (bool, error) {
-	if participant.TelegramId != nil && *participant.TelegramId == userId {
-		return true, nil
-	} else if participant.InvitedBy != nil && *participant.InvitedBy.TelegramId == userId {
-		return true, nil
-	} else {
-		resp, err := b.bot.GetChatAdministrators(tgbotapi.ChatAdministratorsConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: chatId}})
-		if err != nil {
-			log.Error().Msgf("Failed to get chat administrators: %s.", err)
-			return false, err
+// localize uses the language of the user in a private chat, the language set for the chat otherwise.
+func localize(chatId int64, user *tgbotapi.User, settings *model.ChatSettings) i18n.Localizer {
+	if chatId > 0 && user != nil {
+		if lang, ok := i18n.Match(user.LanguageCode); ok {
+			return i18n.For(lang)
 		}
-		for _, member := range resp {
-			if userId == member.User.ID && (member.IsCreator() || member.IsAdministrator()) {
-				return true, nil
-			}
-		}
-		return false, nil
 	}
+	return i18n.For(settings.Language)
 }
 
 func getSelf(update tgbotapi.Update) *model.Participant {
-	tgUser := update.Message.From
-	var name string
-	if len(strings.TrimSpace(tgUser.UserName)) > 0 {
-		name = tgUser.UserName
-	} else if len(strings.TrimSpace(tgUser.FirstName)) > 0 {
-		name = fmt.Sprintf("%s %s", tgUser.FirstName, tgUser.LastName)
-	} else {
-		name = strconv.FormatInt(tgUser.ID, 10)
-	}
+	return newParticipant(update.Message.From)
+}
+
+func newParticipant(tgUser *tgbotapi.User) *model.Participant {
 	return &model.Participant{
-		Name:       name,
+		Name:       displayName(tgUser),
 		TelegramId: &tgUser.ID,
+		Username:   model.NormalizeUsername(tgUser.UserName),
 	}
 }
 
-func hasArguments(message *tgbotapi.Message) bool {
-	return len(strings.TrimSpace(message.CommandArguments())) > 0
+// rejection renders the errors of the service refusing a change, ok is false for other errors.
+func rejection(l i18n.Localizer, err error) (text string, outcome string, ok bool) {
+	var denied *service.PermissionDeniedError
+	var cancelled *service.EventCancelledError
+	switch {
+	case errors.Is(err, service.ErrGuestsNotAllowed):
+		return l.T("guests.not.allowed"), metrics.OutcomeSuccess, true
+	case errors.Is(err, service.ErrGuestLimit):
+		return l.T("guests.limit"), metrics.OutcomeSuccess, true
+	case errors.Is(err, service.ErrCapacityFull):
+		return l.T("event.full"), metrics.OutcomeSuccess, true
+	case errors.Is(err, service.ErrNoActiveEvent):
+		return l.T("event.none"), metrics.OutcomeSuccess, true
+	case errors.As(err, &cancelled) && cancelled.Event.CancelReason != "":
+		return l.T("event.cancelled.reason", cancelled.Event.Title, cancelled.Event.CancelReason), metrics.OutcomeSuccess, true
+	case errors.As(err, &cancelled):
+		return l.T("event.cancelled", cancelled.Event.Title), metrics.OutcomeSuccess, true
+	case errors.Is(err, service.ErrEventClosed):
+		return l.T("event.closed"), metrics.OutcomeSuccess, true
+	case errors.Is(err, service.ErrRegistrationNotOpen):
+		return l.T("registration.not.open"), metrics.OutcomeSuccess, true
+	case errors.Is(err, service.ErrRegistrationClosed):
+		return l.T("registration.closed"), metrics.OutcomeSuccess, true
+	case errors.As(err, &denied):
+		return deniedText(l, denied), metrics.OutcomePermissionDenied, true
+	}
+	return "", "", false
 }
 
-func (b *TgBot) renderEvent(event Event) string {
-	var doc bytes.Buffer
-	err := b.eventRenderingTemplate.ExecuteTemplate(&doc, "event", event)
-	if err != nil {
-		log.Error().Msgf("Failed to render the event %s.", event.Id)
+// deniedText explains which operation the policy doesn't allow.
+func deniedText(l i18n.Localizer, denied *service.PermissionDeniedError) string {
+	name := ""
+	if denied.Participant != nil {
+		name = denied.Participant.Name
+	}
+	switch denied.Operation {
+	case model.OpCreateEvent:
+		return l.T("event.create.denied")
+	case model.OpAddOthers:
+		return l.T("participant.add.denied", name)
+	case model.OpRemove:
+		return l.T("participant.remove.denied", name)
+	case model.OpMarkPaid:
+		return l.T("paid.denied")
+	case model.OpEditOthers:
+		return l.T("participant.move.denied")
+	case model.OpSchedule:
+		return l.T("registration.denied")
+	case model.OpCancel:
+		return l.T("cancel.denied")
+	default:
+		return l.T("participant.edit.denied", name)
+	}
+}
+
+// isDuplicate checks if the service skipped the update as already applied, there is nothing to reply then.
+func isDuplicate(update tgbotapi.Update, err error) bool {
+	if errors.Is(err, service.ErrDuplicateRequest) {
+		log.Info().Msgf("The update %d was already applied.", update.UpdateID)
+		return true
 	}
-	return doc.String()
+	return false
 }
 
-//go:embed event.gohtml
-var templateStr string
+func hasArguments(message *tgbotapi.Message) bool {
+	return len(strings.TrimSpace(message.CommandArguments())) > 0
+}
 
-func getTemplate() (*templating.Template, error) {
-	t, err := templating.New("event").Funcs(sprig.FuncMap()).Parse(templateStr)
-	if err != nil {
-		log.Error().Msgf("Failed to get the template: %s.", err)
-		return nil, err
+// cutArgument splits the first argument off the rest of the arguments, they are separated by any whitespace.
+func cutArgument(arguments string) (string, string) {
+	arguments = strings.TrimSpace(arguments)
+	if idx := strings.IndexFunc(arguments, unicode.IsSpace); idx >= 0 {
+		return arguments[:idx], strings.TrimSpace(arguments[idx:])
 	}
-	return t, nil
+	return arguments, ""
 }
@@ -0,0 +1,64 @@
+package tgbot
+
+import (
+	"errors"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/service"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRejection_PermissionDenied(t *testing.T) {
+	l := i18n.For("en")
+	denied := &service.PermissionDeniedError{Operation: model.OpRemove, Participant: &model.Participant{Name: "Bob"}}
+
+	text, outcome, ok := rejection(l, fmt.Errorf("failed to remove: %w", denied))
+	assert.True(t, ok)
+	assert.Equal(t, metrics.OutcomePermissionDenied, outcome)
+	assert.Equal(t, l.T("participant.remove.denied", "Bob"), text)
+
+	for _, op := range model.Operations {
+		text, _, _ := rejection(l, &service.PermissionDeniedError{Operation: op, Participant: &model.Participant{Name: "Bob"}})
+		assert.NotContainsf(t, text, "%!", "Bad denial of %s", op)
+	}
+
+	_, _, ok = rejection(l, errors.New("datastore is down"))
+	assert.False(t, ok)
+}
+
+func TestRejection_DomainErrors(t *testing.T) {
+	l := i18n.For("en")
+	cases := map[error]string{
+		service.ErrNoActiveEvent:    l.T("event.none"),
+		service.ErrEventClosed:      l.T("event.closed"),
+		service.ErrCapacityFull:     l.T("event.full"),
+		service.ErrGuestsNotAllowed: l.T("guests.not.allowed"),
+	}
+	for err, expected := range cases {
+		text, outcome, ok := rejection(l, fmt.Errorf("failed: %w", err))
+		assert.True(t, ok, err)
+		assert.Equal(t, metrics.OutcomeSuccess, outcome, err)
+		assert.Equal(t, expected, text, err)
+	}
+
+	_, _, ok := rejection(l, service.ErrParticipantNotFound)
+	assert.False(t, ok)
+}
+
+func TestRejection_EventCancelled(t *testing.T) {
+	l := i18n.For("en")
+	event := &model.Event{Title: "Football"}
+	event.Cancel("")
+
+	text, _, ok := rejection(l, fmt.Errorf("failed: %w", &service.EventCancelledError{Event: event}))
+	assert.True(t, ok)
+	assert.Equal(t, l.T("event.cancelled", "Football"), text)
+
+	event.Cancel("rain")
+	text, _, _ = rejection(l, &service.EventCancelledError{Event: event})
+	assert.Equal(t, l.T("event.cancelled.reason", "Football", "rain"), text)
+	assert.ErrorIs(t, &service.EventCancelledError{Event: event}, service.ErrEventClosed)
+}
@@ -0,0 +1,70 @@
+package tgbot
+
+import (
+	"context"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/tracing"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"path"
+	"time"
+)
+
+// instrumentedClient records metrics and spans of every Telegram Bot API call, the method is the last segment
+// of the URL path. The URL itself isn't recorded as it contains the bot token.
+type instrumentedClient struct {
+	client tgbotapi.HTTPClient
+}
+
+func newInstrumentedClient(client tgbotapi.HTTPClient) *instrumentedClient {
+	return &instrumentedClient{client: client}
+}
+
+func (c *instrumentedClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	method := path.Base(req.URL.Path)
+	ctx, span := tracer.Start(req.Context(), "telegram."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("telegram.method", method)))
+	defer span.End()
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	outcome := metrics.OutcomeSuccess
+	if err != nil {
+		outcome = metrics.OutcomeError
+		tracing.RecordError(span, err)
+	} else {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= http.StatusBadRequest {
+			outcome = metrics.OutcomeError
+			span.SetStatus(codes.Error, resp.Status)
+		}
+	}
+	metrics.ObserveTelegramRequest(method, outcome, start)
+	return resp, err
+}
+
+// contextClient attaches the context to the requests, so they are cancelled and traced along with it.
+type contextClient struct {
+	ctx    context.Context
+	client tgbotapi.HTTPClient
+}
+
+func (c *contextClient) Do(req *http.Request) (*http.Response, error) {
+	return c.client.Do(req.WithContext(c.ctx))
+}
+
+func newBotAPI(tgKey string) (*tgbotapi.BotAPI, error) {
+	return tgbotapi.NewBotAPIWithClient(tgKey, tgbotapi.APIEndpoint, newInstrumentedClient(&http.Client{}))
+}
+
+// api returns a copy of the client making the requests within the context.
+// The library doesn't accept a context, the copy shares everything else with the original client.
+func (b *TgBot) api(ctx context.Context) *tgbotapi.BotAPI {
+	api := *b.bot
+	api.Client = &contextClient{ctx: ctx, client: b.bot.Client}
+	return &api
+}
@@ -0,0 +1,236 @@
+package tgbot
+
+import (
+	"context"
+	"event-gorganizer/internal/metrics"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultWorkers   = 8
+	defaultQueueSize = 64
+	statsInterval    = time.Minute
+)
+
+// dispatcher spreads updates over a fixed set of workers. All updates of a chat
+// land on the same worker, so they are handled in the order they arrived, while
+// different chats are processed in parallel. Each worker has a bounded queue,
+// dispatch blocks when it is full, which slows down the updates source.
+type dispatcher struct {
+	queues []chan job
+	handle func(ctx context.Context, update tgbotapi.Update, chatId int64)
+	wg     sync.WaitGroup
+	done   chan struct{}
+
+	// ctx is passed to the handlers, it's cancelled when draining the queues takes too long.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mu guards stopped, dispatch holds it for reading while registering in sending. The queues are closed
+	// once the registered sends are over, so nothing is sent to a closed queue.
+	mu        sync.RWMutex
+	stopped   bool
+	sending   sync.WaitGroup
+	closeOnce sync.Once
+
+	enqueued  atomic.Int64
+	processed atomic.Int64
+	blocked   atomic.Int64
+}
+
+// job is an update with the chat it's handled for, the chat of the update unless it changes another one.
+type job struct {
+	update tgbotapi.Update
+	chatId int64
+}
+
+// DispatcherStats is a snapshot of the dispatcher queues.
+type DispatcherStats struct {
+	QueueDepth []int
+	Enqueued   int64
+	Processed  int64
+	Blocked    int64
+}
+
+func newDispatcher(workers int, queueSize int, handle func(ctx context.Context, update tgbotapi.Update, chatId int64)) *dispatcher {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	queues := make([]chan job, workers)
+	for i := range queues {
+		queues[i] = make(chan job, queueSize)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &dispatcher{
+		queues: queues,
+		handle: handle,
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (d *dispatcher) start() {
+	for _, queue := range d.queues {
+		d.wg.Add(1)
+		go d.work(queue)
+	}
+	go d.reportStats()
+}
+
+// dispatch puts the update to the queue of the worker owning its chat.
+// It returns false when the dispatcher is already stopped.
+func (d *dispatcher) dispatch(update tgbotapi.Update) bool {
+	return d.dispatchFor(chatKey(update), update)
+}
+
+// dispatchFor puts the update to the queue of the worker owning the chat, the update is handled for the chat.
+// It returns false when the dispatcher is already stopped.
+func (d *dispatcher) dispatchFor(chatId int64, update tgbotapi.Update) bool {
+	d.mu.RLock()
+	if d.stopped {
+		d.mu.RUnlock()
+		return false
+	}
+	d.sending.Add(1)
+	d.mu.RUnlock()
+	defer d.sending.Done()
+	queue := d.queues[shardIdx(chatId, len(d.queues))]
+	j := job{update: update, chatId: chatId}
+	select {
+	case queue <- j:
+	default:
+		d.blocked.Add(1)
+		metrics.ObserveUpdateBlocked()
+		log.Warn().Msgf("Update queue is full, waiting to enqueue the update %d.", update.UpdateID)
+		select {
+		case queue <- j:
+		case <-d.done:
+			return false
+		}
+	}
+	d.enqueued.Add(1)
+	return true
+}
+
+// stop closes the queues and waits until the workers handle the remaining updates, the updates waiting
+// for a place in a full queue are rejected. If the context expires first, the handlers context is cancelled
+// and the context error is returned.
+func (d *dispatcher) stop(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.stopped {
+		d.stopped = true
+		close(d.done)
+	}
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.sending.Wait()
+		d.closeOnce.Do(func() {
+			for _, queue := range d.queues {
+				close(queue)
+			}
+		})
+		d.wg.Wait()
+		close(drained)
+	}()
+	defer d.cancel()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		s := d.stats()
+		log.Warn().Msgf("Stopped waiting for the updates, %d of %d handled.", s.Processed, s.Enqueued)
+		return ctx.Err()
+	}
+}
+
+func (d *dispatcher) queueDepth() int {
+	depth := 0
+	for _, queue := range d.queues {
+		depth += len(queue)
+	}
+	return depth
+}
+
+func (d *dispatcher) stats() DispatcherStats {
+	depth := make([]int, len(d.queues))
+	for i, queue := range d.queues {
+		depth[i] = len(queue)
+	}
+	return DispatcherStats{
+		QueueDepth: depth,
+		Enqueued:   d.enqueued.Load(),
+		Processed:  d.processed.Load(),
+		Blocked:    d.blocked.Load(),
+	}
+}
+
+func (d *dispatcher) work(queue chan job) {
+	defer d.wg.Done()
+	for j := range queue {
+		d.handleSafely(j)
+		d.processed.Add(1)
+	}
+}
+
+func (d *dispatcher) handleSafely(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Msgf("Panic while handling the update %d: %v.", j.update.UpdateID, r)
+		}
+	}()
+	d.handle(d.ctx, j.update, j.chatId)
+}
+
+func (d *dispatcher) reportStats() {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			s := d.stats()
+			log.Debug().
+				Ints("queue_depth", s.QueueDepth).
+				Int64("enqueued", s.Enqueued).
+				Int64("processed", s.Processed).
+				Int64("blocked", s.Blocked).
+				Msg("Update dispatcher stats.")
+		}
+	}
+}
+
+// chatKey returns the id used to keep the updates order, updates without a chat are ordered by the sender.
+// The buttons sent to the private chat are ordered with the group whose event they change.
+func chatKey(update tgbotapi.Update) int64 {
+	if query := update.CallbackQuery; query != nil && query.Message != nil {
+		switch data := query.Data; {
+		case strings.HasPrefix(data, privateCallbackPrefix):
+			if _, chatId, ok := parsePrivateCallbackData(data); ok {
+				return chatId
+			}
+		case strings.HasPrefix(data, guestsCallbackPrefix):
+			if _, _, chatId, ok := parseGuestsCallbackData(data, query.Message.Chat.ID); ok {
+				return chatId
+			}
+		}
+	}
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID
+	}
+	if user := update.SentFrom(); user != nil {
+		return user.ID
+	}
+	return 0
+}
@@ -0,0 +1,165 @@
+package tgbot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_KeepsOrderWithinChat(t *testing.T) {
+	var mu sync.Mutex
+	handled := make(map[int64][]int)
+	d := newDispatcher(4, 2, func(ctx context.Context, update tgbotapi.Update, chatId int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled[chatId] = append(handled[chatId], update.UpdateID)
+	})
+	d.start()
+	for i := 0; i < 100; i++ {
+		d.dispatch(newChatUpdate(i, int64(i%3)))
+	}
+	assert.NoError(t, d.stop(context.Background()))
+
+	assert.Len(t, handled, 3)
+	for chatId, ids := range handled {
+		assert.IsIncreasingf(t, ids, "Updates of the chat %d handled out of order", chatId)
+	}
+	assert.Equal(t, int64(100), d.stats().Processed)
+}
+
+func TestDispatcher_ProcessesChatsInParallel(t *testing.T) {
+	release := make(chan struct{})
+	fastHandled := make(chan struct{})
+	d := newDispatcher(2, 1, func(ctx context.Context, update tgbotapi.Update, chatId int64) {
+		if update.Message.Chat.ID == 0 {
+			<-release
+		} else {
+			close(fastHandled)
+		}
+	})
+	d.start()
+	d.dispatch(newChatUpdate(1, 0))
+	d.dispatch(newChatUpdate(2, 1))
+
+	select {
+	case <-fastHandled:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Slow chat blocked other chats")
+	}
+	close(release)
+	assert.NoError(t, d.stop(context.Background()))
+}
+
+func TestDispatcher_RecoversFromPanic(t *testing.T) {
+	handled := 0
+	d := newDispatcher(1, 1, func(ctx context.Context, update tgbotapi.Update, chatId int64) {
+		handled++
+		if update.UpdateID == 1 {
+			panic("boom")
+		}
+	})
+	d.start()
+	d.dispatch(newChatUpdate(1, 0))
+	d.dispatch(newChatUpdate(2, 0))
+	assert.NoError(t, d.stop(context.Background()))
+
+	assert.Equal(t, 2, handled)
+}
+
+func TestDispatcher_RejectsUpdatesWhenStopped(t *testing.T) {
+	d := newDispatcher(1, 1, func(ctx context.Context, update tgbotapi.Update, chatId int64) {})
+	d.start()
+	assert.NoError(t, d.stop(context.Background()))
+
+	assert.False(t, d.dispatch(newChatUpdate(1, 0)))
+}
+
+func TestDispatcher_CancelsHandlersOnStopTimeout(t *testing.T) {
+	cancelled := make(chan struct{})
+	d := newDispatcher(1, 1, func(ctx context.Context, update tgbotapi.Update, chatId int64) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+	d.start()
+	d.dispatch(newChatUpdate(1, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.stop(ctx), context.DeadlineExceeded)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Handler context was not cancelled")
+	}
+}
+
+func TestDispatcher_StopDoesNotWaitForBlockedDispatch(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	d := newDispatcher(1, 1, func(ctx context.Context, update tgbotapi.Update, chatId int64) {
+		if update.UpdateID == 1 {
+			close(started)
+		}
+		<-release
+	})
+	defer close(release)
+	d.start()
+	d.dispatch(newChatUpdate(1, 0))
+	<-started
+	d.dispatch(newChatUpdate(2, 0))
+	rejected := make(chan bool)
+	go func() {
+		rejected <- !d.dispatch(newChatUpdate(3, 0))
+	}()
+	assert.Eventually(t, func() bool { return d.stats().Blocked == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	stopped := make(chan error)
+	go func() {
+		stopped <- d.stop(ctx)
+	}()
+	select {
+	case err := <-stopped:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		assert.Fail(t, "Stop waited for the blocked dispatch")
+	}
+	assert.True(t, <-rejected)
+}
+
+func TestDispatcher_DispatchForHandlesTheUpdateForTheChat(t *testing.T) {
+	var handledFor int64
+	d := newDispatcher(2, 1, func(ctx context.Context, update tgbotapi.Update, chatId int64) {
+		handledFor = chatId
+	})
+	d.start()
+	assert.True(t, d.dispatchFor(-100, newChatUpdate(1, 111)))
+	assert.NoError(t, d.stop(context.Background()))
+
+	assert.Equal(t, int64(-100), handledFor)
+}
+
+func TestChatKey_PrivateButtonsFollowTheGroup(t *testing.T) {
+	callback := func(data string) tgbotapi.Update {
+		return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: 111},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 111}},
+			Data:    data,
+		}}
+	}
+
+	assert.Equal(t, int64(-100), chatKey(callback("private:join:-100")))
+	assert.Equal(t, int64(-100), chatKey(callback(guestsCallbackData("remove", 111, -100))))
+	assert.Equal(t, int64(111), chatKey(callback(notifyCallbackPrefix+"show")))
+	assert.Equal(t, int64(111), chatKey(callback("private:join:oops")))
+}
+
+func newChatUpdate(updateId int, chatId int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateId,
+		Message:  &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatId}},
+	}
+}
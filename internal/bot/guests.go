@@ -0,0 +1,138 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/service"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// guestsCallbackPrefix marks the buttons asking whether to remove the guests of a participant who left,
+// the data is guests:<remove|keep>:<inviter id>:<chat id>. The chat is the group of the event, the prompt
+// may be sent in the private chat.
+const guestsCallbackPrefix = "guests:"
+
+// promptGuestRemoval asks the participant who left whether to remove their guests too.
+func (b *TgBot) promptGuestRemoval(ctx context.Context, chatId int64, inviter *model.Participant, l i18n.Localizer, msg *tgbotapi.MessageConfig) {
+	event, err := b.eventService.GetActiveEvent(ctx, chatId)
+	if err != nil {
+		log.Warn().Msgf("Failed to get the guests of %s in the chat %d: %s.", inviter.Name, chatId, err)
+		return
+	}
+	guests := event.GuestsOf(inviter.Id())
+	if len(guests) == 0 {
+		return
+	}
+	msg.Text += "\n" + l.N("guests.prompt", len(guests), guestNames(guests))
+	data := func(action string) string {
+		return guestsCallbackData(action, *inviter.TelegramId, chatId)
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("guests.remove.button"), data("remove")),
+		tgbotapi.NewInlineKeyboardButtonData(l.T("guests.keep.button"), data("keep")),
+	))
+}
+
+// handleGuestsCallback removes or keeps the guests as the inviter answered the prompt, the answer replaces
+// the buttons.
+func (b *TgBot) handleGuestsCallback(ctx context.Context, update tgbotapi.Update) (outcome string) {
+	query := update.CallbackQuery
+	if query.Message == nil {
+		b.answerCallback(ctx, query, "")
+		return
+	}
+	messageChatId := query.Message.Chat.ID
+	action, inviterId, chatId, ok := parseGuestsCallbackData(query.Data, messageChatId)
+
+	ctx, span := tracer.Start(ctx, "callback", trace.WithAttributes(
+		attribute.Int("update.id", update.UpdateID),
+		attribute.Int64("chat.id", chatId),
+		attribute.String("guests", action),
+	))
+	defer span.End()
+	outcome = metrics.OutcomeSuccess
+	defer func(start time.Time) {
+		metrics.ObserveCommand("guests", outcome, start)
+		span.SetAttributes(attribute.String("outcome", outcome))
+	}(time.Now())
+
+	l := b.localizer(ctx, messageChatId, query.From)
+	if !ok || inviterId != query.From.ID {
+		outcome = metrics.OutcomePermissionDenied
+		b.answerCallback(ctx, query, l.T("guests.prompt.denied"))
+		return
+	}
+	text := l.T("guests.kept")
+	if action == "remove" {
+		actor := newChatUser(chatId, query.From)
+		inviter := &model.Participant{Name: actor.Name, TelegramId: &inviterId, Username: actor.Username}
+		removed, err := b.eventService.RemoveGuests(ctx, chatId, inviter, actor)
+		if isDuplicate(update, err) {
+			b.answerCallback(ctx, query, "")
+			return
+		}
+		if rejected, rejectedOutcome, ok := rejection(l, err); ok {
+			outcome = rejectedOutcome
+			b.answerCallback(ctx, query, rejected)
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrParticipantNotFound):
+			text = l.T("guests.none")
+		case err != nil:
+			log.Error().Msgf("Failed to remove the guests of %d in the chat %d: %s.", inviterId, chatId, err)
+			outcome = metrics.OutcomeError
+			b.answerCallback(ctx, query, l.T("guests.remove.failed"))
+			return
+		default:
+			text = l.T("guests.removed", guestNames(removed))
+		}
+	}
+	b.answerCallback(ctx, query, "")
+	// the edited message has no buttons anymore
+	edit := tgbotapi.NewEditMessageText(messageChatId, query.Message.MessageID, query.Message.Text+"\n"+text)
+	b.sender.enqueue(ctx, messageChatId, edit)
+	return
+}
+
+func guestNames(guests []*model.Participant) string {
+	names := make([]string, 0, len(guests))
+	for _, guest := range guests {
+		names = append(names, guest.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func guestsCallbackData(action string, inviterId int64, chatId int64) string {
+	return fmt.Sprintf("%s%s:%d:%d", guestsCallbackPrefix, action, inviterId, chatId)
+}
+
+// parseGuestsCallbackData reads the buttons of the prompt, the buttons sent before the data had the chat are
+// answered in the chat of the message.
+func parseGuestsCallbackData(data string, messageChatId int64) (action string, inviterId int64, chatId int64, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(data, guestsCallbackPrefix), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", 0, 0, false
+	}
+	inviterId, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, 0, false
+	}
+	chatId = messageChatId
+	if len(parts) == 3 {
+		if chatId, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+			return "", 0, 0, false
+		}
+	}
+	return parts[0], inviterId, chatId, true
+}
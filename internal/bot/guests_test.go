@@ -0,0 +1,25 @@
+package tgbot
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGuestsCallbackData_KeepsTheGroup(t *testing.T) {
+	data := guestsCallbackData("remove", 111, -100)
+	assert.LessOrEqual(t, len(guestsCallbackData("remove", 1<<62, -1<<62)), 64, "Longer than Telegram allows")
+
+	// the prompt was answered in the private chat
+	action, inviterId, chatId, ok := parseGuestsCallbackData(data, 111)
+	assert.True(t, ok)
+	assert.Equal(t, "remove", action)
+	assert.Equal(t, int64(111), inviterId)
+	assert.Equal(t, int64(-100), chatId)
+
+	_, _, chatId, ok = parseGuestsCallbackData("guests:keep:111", -200)
+	assert.True(t, ok)
+	assert.Equal(t, int64(-200), chatId, "Buttons without the chat answer in the chat of the message")
+
+	_, _, _, ok = parseGuestsCallbackData("guests:remove:bob", -200)
+	assert.False(t, ok)
+}
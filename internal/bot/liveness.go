@@ -0,0 +1,41 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const (
+	telegramCheckInterval = 30 * time.Second
+	// telegramStaleAfter allows a couple of failed getMe calls in a row before the bot is reported unready.
+	telegramStaleAfter = 3 * telegramCheckInterval
+)
+
+// CheckTelegram reports an error if getMe didn't succeed recently.
+func (b *TgBot) CheckTelegram(ctx context.Context) error {
+	lastSuccess := time.Unix(0, b.lastGetMe.Load())
+	if time.Since(lastSuccess) > telegramStaleAfter {
+		return fmt.Errorf("last successful getMe at %s", lastSuccess.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// watchTelegram calls getMe periodically until the context is cancelled.
+func (b *TgBot) watchTelegram(ctx context.Context) {
+	ticker := time.NewTicker(telegramCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := b.api(ctx).GetMe(); err != nil {
+				log.Warn().Msgf("Failed to call getMe: %s.", err)
+			} else {
+				b.lastGetMe.Store(time.Now().UnixNano())
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package tgbot
+
+import (
+	"context"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"slices"
+	"strings"
+	"time"
+)
+
+// notifyCallbackPrefix marks the buttons of the notification preferences, the data is notify:<topic> to toggle
+// the topic or notify:show to open the preferences from the dashboard.
+const notifyCallbackPrefix = "notify:"
+
+// Notify sends the notification to the user in the private chat, users who opted in started the bot,
+// so the bot may write to them.
+func (b *TgBot) Notify(ctx context.Context, notification model.Notification, preferences *model.NotificationPreferences) {
+	l := i18n.For(preferences.Language)
+	b.sender.enqueue(ctx, notification.UserId, tgbotapi.NewMessage(notification.UserId, notificationText(l, notification)))
+}
+
+func notificationText(l i18n.Localizer, n model.Notification) string {
+	switch n.Kind {
+	case model.NotificationPromoted:
+		return l.T("notification.promoted", n.Participant, n.Event)
+	case model.NotificationAdded:
+		return l.T("notification.added", n.Actor, n.Event)
+	case model.NotificationRemoved:
+		return l.T("notification.removed", n.Actor, n.Event)
+	case model.NotificationRescheduled:
+		return l.T("notification.rescheduled", n.Event, n.Details)
+	case model.NotificationCancelled:
+		if n.Details != "" {
+			return l.T("notification.cancelled.reason", n.Actor, n.Event, n.Details)
+		}
+		return l.T("notification.cancelled", n.Actor, n.Event)
+	case model.NotificationPaymentDue:
+		return l.T("notification.payment", n.Details, n.Event)
+	}
+	return n.Event
+}
+
+// remindUnpaid handles /remind, the users who owe for the event get a private message if they opted in.
+func (b *TgBot) remindUnpaid(ctx context.Context, update tgbotapi.Update, chatId int64, l i18n.Localizer) (string, string) {
+	reminded, err := b.eventService.RemindUnpaid(ctx, chatId, newChatUser(chatId, update.Message.From))
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to remind the participants of the chat %d to pay: %s.", chatId, err)
+		return l.T("remind.failed"), metrics.OutcomeError
+	}
+	return l.N("remind.sent", reminded), metrics.OutcomeSuccess
+}
+
+// handleNotifyCallback shows the notification preferences of the user or toggles a topic, the message is edited
+// to show the preferences.
+func (b *TgBot) handleNotifyCallback(ctx context.Context, update tgbotapi.Update) (outcome string) {
+	query := update.CallbackQuery
+	if query.Message == nil {
+		b.answerCallback(ctx, query, "")
+		return
+	}
+	chatId := query.Message.Chat.ID
+	topic := strings.TrimPrefix(query.Data, notifyCallbackPrefix)
+
+	ctx, span := tracer.Start(ctx, "callback", trace.WithAttributes(
+		attribute.Int("update.id", update.UpdateID),
+		attribute.Int64("chat.id", chatId),
+		attribute.String("notify", topic),
+	))
+	defer span.End()
+	outcome = metrics.OutcomeSuccess
+	defer func(start time.Time) {
+		metrics.ObserveCommand("notify", outcome, start)
+		span.SetAttributes(attribute.String("outcome", outcome))
+	}(time.Now())
+
+	l := b.localizer(ctx, chatId, query.From)
+	var preferences *model.NotificationPreferences
+	var err error
+	if topic == "show" {
+		preferences, err = b.eventService.GetNotificationPreferences(ctx, query.From.ID)
+	} else {
+		preferences, err = b.eventService.ToggleNotifications(ctx, query.From.ID, model.NotificationTopic(topic), l.Lang())
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to change the notifications of the user %d: %s.", query.From.ID, err)
+		outcome = metrics.OutcomeError
+		b.answerCallback(ctx, query, l.T("notifications.failed"))
+		return
+	}
+	b.answerCallback(ctx, query, "")
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatId, query.Message.MessageID, l.T("notifications.title"), notificationsKeyboard(l, preferences))
+	b.sender.enqueue(ctx, chatId, edit)
+	return
+}
+
+// notificationsKeyboard has a button per topic switching it on or off.
+func notificationsKeyboard(l i18n.Localizer, preferences *model.NotificationPreferences) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(model.NotificationTopics))
+	for _, topic := range model.NotificationTopics {
+		mark := "🔕"
+		if slices.Contains(preferences.Topics, topic) {
+			mark = "🔔"
+		}
+		label := mark + " " + l.T("notifications.topic."+string(topic))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(label, notifyCallbackPrefix+string(topic))))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,24 @@
+package tgbot
+
+import (
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNotificationText_AllKindsLocalized(t *testing.T) {
+	kinds := []model.NotificationKind{
+		model.NotificationPromoted, model.NotificationAdded, model.NotificationRemoved,
+		model.NotificationRescheduled, model.NotificationPaymentDue, model.NotificationCancelled,
+	}
+	for _, lang := range i18n.Languages() {
+		for _, kind := range kinds {
+			notification := model.Notification{Kind: kind, Event: "Football", Participant: "Bob", Actor: "@alice", Details: "details"}
+			text := notificationText(i18n.For(lang), notification)
+			assert.NotContainsf(t, text, "notification.", "Missing %s translation of %s", lang, kind)
+			assert.NotContainsf(t, text, "%!", "Bad %s format of %s", lang, kind)
+			assert.Containsf(t, text, "Football", "The %s notification of %s doesn't name the event", lang, kind)
+		}
+	}
+}
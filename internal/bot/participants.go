@@ -0,0 +1,100 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"strings"
+)
+
+// renameParticipant handles /rename N New Name.
+func (b *TgBot) renameParticipant(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	numberArg, name := cutArgument(update.Message.CommandArguments())
+	number, err := strconv.Atoi(numberArg)
+	if err != nil || name == "" {
+		return l.T("participant.rename.usage"), metrics.OutcomeError
+	}
+	renamed, err := b.eventService.RenameParticipant(ctx, chatId, number, name, newChatUser(chatId, update.Message.From))
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	switch {
+	case isDuplicate(update, err):
+		return "", metrics.OutcomeSuccess
+	case errors.Is(err, service.ErrParticipantNotFound):
+		return l.T("participant.not.found", number), metrics.OutcomeError
+	case errors.Is(err, model.ErrNameTaken):
+		return l.T("participant.name.taken", name), metrics.OutcomeError
+	case err != nil:
+		log.Error().Msgf("Failed to rename %d in the chat %d: %s.", number, chatId, err)
+		return l.T("participant.edit.failed", number), metrics.OutcomeError
+	}
+	return l.T("participant.renamed", number, renamed.Name), metrics.OutcomeSuccess
+}
+
+// moveParticipant handles /move N M. Moving later is allowed like other changes, moving ahead of others
+// needs the permission to edit others.
+func (b *TgBot) moveParticipant(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	numberArg, toArg := cutArgument(update.Message.CommandArguments())
+	number, err := strconv.Atoi(numberArg)
+	to, toErr := strconv.Atoi(toArg)
+	if err != nil || toErr != nil {
+		return l.T("participant.move.usage"), metrics.OutcomeError
+	}
+	moved, err := b.eventService.MoveParticipant(ctx, chatId, number, to, newChatUser(chatId, update.Message.From))
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	switch {
+	case isDuplicate(update, err):
+		return "", metrics.OutcomeSuccess
+	case errors.Is(err, service.ErrParticipantNotFound):
+		return l.T("participant.move.missing", number, to), metrics.OutcomeError
+	case err != nil:
+		log.Error().Msgf("Failed to move %d to %d in the chat %d: %s.", number, to, chatId, err)
+		return l.T("participant.edit.failed", number), metrics.OutcomeError
+	}
+	return l.T("participant.moved", moved.Name, moved.Number), metrics.OutcomeSuccess
+}
+
+// changeInviter handles /inviter N @user, or a reply to a message of the new inviter with /inviter N.
+func (b *TgBot) changeInviter(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	target, arguments, err := b.resolveTarget(ctx, chatId, update.Message)
+	if err != nil {
+		return l.T("participant.edit.failed", arguments), metrics.OutcomeError
+	}
+	number, numberErr := strconv.Atoi(strings.TrimSpace(arguments))
+	if target == nil || numberErr != nil {
+		return l.T("participant.inviter.usage"), metrics.OutcomeError
+	}
+	if target.UserId == 0 {
+		return l.T("participant.unknown", target.Mention()), metrics.OutcomeError
+	}
+	userId := target.UserId
+	inviter := &model.Participant{Name: target.Name, Username: target.Username, TelegramId: &userId}
+	guest, err := b.eventService.SetInviter(ctx, chatId, number, inviter, newChatUser(chatId, update.Message.From))
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	switch {
+	case isDuplicate(update, err):
+		return "", metrics.OutcomeSuccess
+	case errors.Is(err, service.ErrParticipantNotFound):
+		return l.T("participant.not.found", number), metrics.OutcomeError
+	case errors.Is(err, model.ErrNotGuest):
+		return l.T("participant.not.guest", number), metrics.OutcomeError
+	case err != nil:
+		log.Error().Msgf("Failed to change the inviter of %d in the chat %d: %s.", number, chatId, err)
+		return l.T("participant.edit.failed", number), metrics.OutcomeError
+	}
+	return l.T("participant.inviter.changed", guest.Name, inviter.Name), metrics.OutcomeSuccess
+}
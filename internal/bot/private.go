@@ -0,0 +1,345 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/service"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// privateCallbackPrefix marks the join and leave buttons of the dashboard, the data is private:<join|leave>:<chat id>.
+const privateCallbackPrefix = "private:"
+
+// routedCommands change the event of the group selected in the private chat when sent there.
+var routedCommands = map[string]bool{"event": true, "i": true, "cant": true, "paid": true}
+
+// start handles /start. In the private chat it shows the dashboard of the user, a deep link to the chat selects
+// the group first. In a group it links to the private chat.
+func (b *TgBot) start(ctx context.Context, update tgbotapi.Update, l i18n.Localizer, msg *tgbotapi.MessageConfig) string {
+	message := update.Message
+	if !message.Chat.IsPrivate() {
+		msg.Text = l.T("private.open")
+		if keyboard, ok := b.privateChatKeyboard(l, message.Chat.ID); ok {
+			msg.ReplyMarkup = keyboard
+		}
+		return metrics.OutcomeSuccess
+	}
+	var selected string
+	if chatId, ok := model.ParseStartPayload(message.CommandArguments()); ok {
+		title, text, outcome := b.selectGroup(ctx, chatId, message.From, l)
+		if text != "" {
+			msg.Text = text
+			return outcome
+		}
+		selected = l.T("private.selected", title) + "\n\n"
+	}
+	text, keyboard, err := b.dashboard(ctx, message.From, l)
+	if err != nil {
+		log.Error().Msgf("Failed to get the events of the user %d: %s.", message.From.ID, err)
+		msg.Text = l.T("private.failed")
+		return metrics.OutcomeError
+	}
+	msg.Text = selected + text
+	msg.ReplyMarkup = keyboard
+	return metrics.OutcomeSuccess
+}
+
+// selectGroup makes the private chat of the user change the events of the group, it returns the title of the
+// group or the reply when the group can't be selected.
+func (b *TgBot) selectGroup(ctx context.Context, chatId int64, user *tgbotapi.User, l i18n.Localizer) (string, string, string) {
+	member, err := b.isMember(ctx, chatId, user.ID)
+	if err != nil {
+		log.Error().Msgf("Failed to check if the user %d is in the chat %d: %s.", user.ID, chatId, err)
+		return "", l.T("permissions.failed"), metrics.OutcomeError
+	}
+	if !member {
+		return "", l.T("private.not.member"), metrics.OutcomePermissionDenied
+	}
+	chat, err := b.api(ctx).GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: chatId}})
+	if err != nil {
+		log.Error().Msgf("Failed to get the chat %d: %s.", chatId, err)
+		return "", l.T("private.failed"), metrics.OutcomeError
+	}
+	chatUser := newChatUser(chatId, user)
+	chatUser.ChatTitle = chat.Title
+	if err = b.eventService.SelectChat(ctx, chatUser); err != nil {
+		log.Error().Msgf("Failed to select the chat %d for the user %d: %s.", chatId, user.ID, err)
+		return "", l.T("private.failed"), metrics.OutcomeError
+	}
+	return chat.Title, "", ""
+}
+
+// selectedGroup returns the group the commands of the private chat change. It's 0 with the reply when no group
+// was selected or the user isn't in it anymore.
+func (b *TgBot) selectedGroup(ctx context.Context, user *tgbotapi.User, l i18n.Localizer) (int64, string, string) {
+	chatId, err := b.eventService.SelectedChat(ctx, user.ID)
+	if err != nil {
+		log.Error().Msgf("Failed to get the chat selected by the user %d: %s.", user.ID, err)
+		return 0, l.T("private.failed"), metrics.OutcomeError
+	}
+	if chatId == 0 {
+		return 0, l.T("private.select"), metrics.OutcomeSuccess
+	}
+	member, err := b.isMember(ctx, chatId, user.ID)
+	if err != nil {
+		log.Error().Msgf("Failed to check if the user %d is in the chat %d: %s.", user.ID, chatId, err)
+		return 0, l.T("permissions.failed"), metrics.OutcomeError
+	}
+	if !member {
+		return 0, l.T("private.not.member"), metrics.OutcomePermissionDenied
+	}
+	return chatId, "", ""
+}
+
+// isMember checks if the user is in the group, the private chat changes the events of the groups of the user only.
+func (b *TgBot) isMember(ctx context.Context, chatId int64, userId int64) (bool, error) {
+	member, err := b.api(ctx).GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatId, UserID: userId},
+	})
+	if err != nil {
+		return false, err
+	}
+	// restricted users are members only with IsMember set
+	return member.IsCreator() || member.IsAdministrator() || member.Status == "member" || member.IsMember, nil
+}
+
+// privateChatKeyboard links to the private chat with the group selected, ok is false when the bot has no username.
+func (b *TgBot) privateChatKeyboard(l i18n.Localizer, chatId int64) (tgbotapi.InlineKeyboardMarkup, bool) {
+	if b.bot == nil || b.bot.Self.UserName == "" {
+		return tgbotapi.InlineKeyboardMarkup{}, false
+	}
+	link := fmt.Sprintf("https://t.me/%s?start=%s", b.bot.Self.UserName, model.StartPayload(chatId))
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonURL(l.T("private.open.button"), link),
+	)), true
+}
+
+// dashboard lists the upcoming events of the user with the buttons to join or leave them and to change
+// the notifications.
+func (b *TgBot) dashboard(ctx context.Context, user *tgbotapi.User, l i18n.Localizer) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	events, balance, err := b.eventService.Dashboard(ctx, user.ID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	locations := make(map[int64]*time.Location, len(events))
+	for _, groupEvent := range events {
+		settings, err := b.eventService.GetSettings(ctx, groupEvent.Event.ChatId)
+		if err != nil {
+			// the kickoff is shown in UTC then
+			log.Warn().Msgf("Failed to get settings of the chat %d: %s.", groupEvent.Event.ChatId, err)
+			continue
+		}
+		locations[groupEvent.Event.ChatId] = settings.Location()
+	}
+	return dashboardText(l, events, balance, user.ID, locations), dashboardKeyboard(l, events, user.ID), nil
+}
+
+// handlePrivateCallback joins or leaves the event of the group as the user clicked on the dashboard,
+// the dashboard is refreshed after the change.
+func (b *TgBot) handlePrivateCallback(ctx context.Context, update tgbotapi.Update) (outcome string) {
+	query := update.CallbackQuery
+	if query.Message == nil {
+		b.answerCallback(ctx, query, "")
+		return
+	}
+	action, chatId, ok := parsePrivateCallbackData(query.Data)
+
+	ctx, span := tracer.Start(ctx, "callback", trace.WithAttributes(
+		attribute.Int("update.id", update.UpdateID),
+		attribute.Int64("chat.id", query.Message.Chat.ID),
+		attribute.String("private", action),
+	))
+	defer span.End()
+	outcome = metrics.OutcomeSuccess
+	defer func(start time.Time) {
+		metrics.ObserveCommand("private", outcome, start)
+		span.SetAttributes(attribute.String("outcome", outcome))
+	}(time.Now())
+
+	l := b.localizer(ctx, query.Message.Chat.ID, query.From)
+	if !ok {
+		outcome = metrics.OutcomeError
+		b.answerCallback(ctx, query, "")
+		return
+	}
+	member, err := b.isMember(ctx, chatId, query.From.ID)
+	if err != nil {
+		log.Error().Msgf("Failed to check if the user %d is in the chat %d: %s.", query.From.ID, chatId, err)
+		outcome = metrics.OutcomeError
+		b.answerCallback(ctx, query, l.T("permissions.failed"))
+		return
+	}
+	if !member {
+		outcome = metrics.OutcomePermissionDenied
+		b.answerCallback(ctx, query, l.T("private.not.member"))
+		return
+	}
+
+	self := newParticipant(query.From)
+	var text string
+	if action == "join" {
+		text, outcome = b.joinFromPrivate(ctx, update, chatId, self, l)
+	} else {
+		text, outcome = b.leaveFromPrivate(ctx, update, chatId, self, l)
+	}
+	if text == "" {
+		b.answerCallback(ctx, query, "")
+		return
+	}
+	b.answerCallback(ctx, query, text)
+
+	dashboard, keyboard, err := b.dashboard(ctx, query.From, l)
+	if err != nil {
+		log.Warn().Msgf("Failed to refresh the events of the user %d: %s.", query.From.ID, err)
+		return
+	}
+	messageChatId := query.Message.Chat.ID
+	b.sender.enqueue(ctx, messageChatId, tgbotapi.NewEditMessageTextAndMarkup(messageChatId, query.Message.MessageID, dashboard, keyboard))
+	return
+}
+
+// parsePrivateCallbackData returns the action and the group of the button, see privateCallbackPrefix.
+func parsePrivateCallbackData(data string) (action string, chatId int64, ok bool) {
+	action, chatArg, _ := strings.Cut(strings.TrimPrefix(data, privateCallbackPrefix), ":")
+	chatId, err := strconv.ParseInt(chatArg, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return action, chatId, true
+}
+
+// routeToGroup hands the command sent in the private chat over to the worker of the selected group, so it's
+// handled in order with the updates of the group. The user is told when no group can be changed.
+func (b *TgBot) routeToGroup(ctx context.Context, update tgbotapi.Update) {
+	message := update.Message
+	start := time.Now()
+	l := b.localizer(ctx, message.Chat.ID, message.From)
+	groupId, text, outcome := b.selectedGroup(ctx, message.From, l)
+	if groupId == 0 {
+		metrics.ObserveCommand(message.Command(), outcome, start)
+		b.sender.enqueue(ctx, message.Chat.ID, tgbotapi.NewMessage(message.Chat.ID, text))
+		return
+	}
+	if !b.dispatcher.dispatchFor(groupId, update) {
+		// the bot is stopping, the update is handled here rather than lost
+		b.handleUpdate(ctx, update, groupId)
+	}
+}
+
+func (b *TgBot) joinFromPrivate(ctx context.Context, update tgbotapi.Update, chatId int64, self *model.Participant, l i18n.Localizer) (string, string) {
+	_, err := b.eventService.AddNewParticipant(ctx, chatId, self, newChatUser(chatId, update.CallbackQuery.From))
+	if isDuplicate(update, err) {
+		return "", metrics.OutcomeSuccess
+	}
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	switch {
+	case errors.Is(err, service.ErrAlreadyRegistered):
+		return l.T("participant.already.registered", self.Name), metrics.OutcomeSuccess
+	case err != nil:
+		log.Error().Msgf("Failed to add %s: %s.", self.Name, err)
+		return l.T("participant.add.failed", self.Name), metrics.OutcomeError
+	}
+	return l.T("participant.added", self.Name), metrics.OutcomeSuccess
+}
+
+func (b *TgBot) leaveFromPrivate(ctx context.Context, update tgbotapi.Update, chatId int64, self *model.Participant, l i18n.Localizer) (string, string) {
+	_, err := b.eventService.RemoveParticipant(ctx, chatId, self, newChatUser(chatId, update.CallbackQuery.From))
+	if isDuplicate(update, err) {
+		return "", metrics.OutcomeSuccess
+	}
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	switch {
+	case errors.Is(err, service.ErrParticipantNotFound):
+		return l.T("participant.not.registered", self.Name), metrics.OutcomeSuccess
+	case err != nil:
+		log.Error().Msgf("Failed to remove %s: %s.", self.Name, err)
+		return l.T("participant.remove.failed", self.Name), metrics.OutcomeError
+	}
+	return l.T("participant.removed", self.Name), metrics.OutcomeSuccess
+}
+
+// dashboardText lists the events with the kickoff in the timezone of the group and whether the user takes part,
+// followed by the balance of the user.
+func dashboardText(l i18n.Localizer, events []model.GroupEvent, balance model.Balance, userId int64, locations map[int64]*time.Location) string {
+	if len(events) == 0 {
+		if len(balance) == 0 {
+			return l.T("private.dashboard.empty")
+		}
+		return l.T("private.dashboard.empty") + "\n\n" + l.T("private.balance.due", balance.String())
+	}
+	var sb strings.Builder
+	sb.WriteString(l.T("private.dashboard.title"))
+	for i, groupEvent := range events {
+		event := groupEvent.Event
+		sb.WriteString(fmt.Sprintf("\n%d. %s", i+1, event.Title))
+		if groupEvent.ChatTitle != "" {
+			sb.WriteString(" (" + groupEvent.ChatTitle + ")")
+		}
+		if !event.StartsAt.IsZero() {
+			loc, ok := locations[event.ChatId]
+			if !ok {
+				loc = time.UTC
+			}
+			sb.WriteString(", " + model.FormatTime(event.StartsAt, loc))
+		}
+		sb.WriteString(": " + participationText(l, event, userId))
+	}
+	sb.WriteString("\n\n")
+	if len(balance) == 0 {
+		sb.WriteString(l.T("private.balance.settled"))
+	} else {
+		sb.WriteString(l.T("private.balance.due", balance.String()))
+	}
+	sb.WriteString("\n" + l.T("private.hint"))
+	return sb.String()
+}
+
+// participationText tells if the user attends the event or is waitlisted and has paid.
+func participationText(l i18n.Localizer, event *model.Event, userId int64) string {
+	participant := event.FindParticipant(strconv.FormatInt(userId, 10))
+	if participant == nil {
+		return l.T("private.status.none")
+	}
+	_, waitlist := event.Lineup()
+	status := l.T("private.status.attending", participant.Number)
+	if slices.Contains(waitlist, participant) {
+		status = l.T("private.status.waitlisted", participant.Number)
+	}
+	if participant.PaymentStatus.Paid {
+		status += " 💰✅"
+	}
+	return status
+}
+
+// dashboardKeyboard has a button per event to join it or to leave it when the user already takes part,
+// and a button opening the notification preferences.
+func dashboardKeyboard(l i18n.Localizer, events []model.GroupEvent, userId int64) tgbotapi.InlineKeyboardMarkup {
+	id := strconv.FormatInt(userId, 10)
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(events)+1)
+	for _, groupEvent := range events {
+		event := groupEvent.Event
+		action, label := "join", l.T("private.join.button", event.Title)
+		if event.FindParticipant(id) != nil {
+			action, label = "leave", l.T("private.leave.button", event.Title)
+		}
+		data := fmt.Sprintf("%s%s:%d", privateCallbackPrefix, action, event.ChatId)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(label, data)))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("notifications.button"), notifyCallbackPrefix+"show")))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
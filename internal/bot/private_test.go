@@ -0,0 +1,36 @@
+package tgbot
+
+import (
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDashboardText(t *testing.T) {
+	l := i18n.For("en")
+	userId := int64(1)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	football := &model.Event{ChatId: -1, Title: "Football", Status: model.StatusOpen, Price: 1000, Currency: "EUR",
+		StartsAt: time.Date(2024, time.May, 25, 16, 0, 0, 0, time.UTC)}
+	football.AddParticipant(&model.Participant{Name: "Alice", TelegramId: &userId})
+	chess := &model.Event{ChatId: -2, Title: "Chess", Status: model.StatusOpen}
+	events := []model.GroupEvent{{ChatTitle: "Sports", Event: football}, {Event: chess}}
+
+	balance := model.Balance{}
+	balance.Add(football, userId)
+	text := dashboardText(l, events, balance, userId, map[int64]*time.Location{-1: berlin})
+	assert.Contains(t, text, "1. Football (Sports), 25.05.2024 18:00: "+l.T("private.status.attending", 1))
+	assert.Contains(t, text, "2. Chess: "+l.T("private.status.none"))
+	assert.Contains(t, text, l.T("private.balance.due", "10.00 EUR"))
+
+	keyboard := dashboardKeyboard(l, events, userId)
+	assert.Equal(t, "private:leave:-1", *keyboard.InlineKeyboard[0][0].CallbackData)
+	assert.Equal(t, "private:join:-2", *keyboard.InlineKeyboard[1][0].CallbackData)
+
+	assert.Equal(t, l.T("private.dashboard.empty"), dashboardText(l, nil, model.Balance{}, userId, nil))
+	// the events which started are still to be paid
+	assert.Contains(t, dashboardText(l, nil, balance, userId, nil), l.T("private.balance.due", "10.00 EUR"))
+}
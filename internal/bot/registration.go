@@ -0,0 +1,101 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const announcementCheckInterval = time.Minute
+
+// scheduleRegistration handles /kickoff <time>, /reschedule <time>, /opens <time>, /close and /reopen. Times are in the timezone
+// of the chat.
+func (b *TgBot) scheduleRegistration(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	actor := newChatUser(chatId, update.Message.From)
+	settings, err := b.eventService.GetSettings(ctx, chatId)
+	if err != nil {
+		log.Error().Msgf("Failed to get settings of the chat %d: %s.", chatId, err)
+		return l.T("registration.failed"), metrics.OutcomeError
+	}
+	loc := settings.Location()
+	var event *model.Event
+	switch command := update.Message.Command(); command {
+	case "kickoff", "opens", "reschedule":
+		at, parseErr := model.ParseTime(update.Message.CommandArguments(), time.Now(), loc)
+		if parseErr != nil {
+			return l.T("registration.usage", command), metrics.OutcomeError
+		}
+		switch command {
+		case "kickoff":
+			event, err = b.eventService.SetKickoff(ctx, chatId, at, actor)
+		case "reschedule":
+			event, err = b.eventService.RescheduleEvent(ctx, chatId, at, actor)
+		default:
+			event, err = b.eventService.OpenRegistrationAt(ctx, chatId, at, actor)
+		}
+	case "close":
+		event, err = b.eventService.CloseRegistration(ctx, chatId, actor)
+	default:
+		event, err = b.eventService.ReopenRegistration(ctx, chatId, actor)
+	}
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	switch {
+	case isDuplicate(update, err):
+		return "", metrics.OutcomeSuccess
+	case errors.Is(err, service.ErrInvalidSchedule):
+		return l.T("registration.invalid"), metrics.OutcomeError
+	case err != nil:
+		log.Error().Msgf("Failed to change the registration of the chat %d: %s.", chatId, err)
+		return l.T("registration.failed"), metrics.OutcomeError
+	}
+	return registrationText(l, event, time.Now(), loc), metrics.OutcomeSuccess
+}
+
+// registrationText describes the kickoff and when registration opens or closes.
+func registrationText(l i18n.Localizer, event *model.Event, now time.Time, loc *time.Location) string {
+	var text string
+	if !event.StartsAt.IsZero() {
+		text = l.T("registration.kickoff", model.FormatTime(event.StartsAt, loc)) + "\n"
+	}
+	switch {
+	case event.RegistrationUpcoming(now):
+		text += l.T("registration.opens", model.FormatTime(event.RegistrationOpensAt, loc))
+	case !event.RegistrationOpen(now):
+		text += l.T("registration.closed")
+	case !event.RegistrationClosesAt.IsZero():
+		text += l.T("registration.closes", model.FormatTime(event.RegistrationClosesAt, loc))
+	default:
+		text += l.T("registration.open")
+	}
+	return text
+}
+
+// announceOpenings tells the chats that registration opened until the context is cancelled.
+func (b *TgBot) announceOpenings(ctx context.Context) {
+	ticker := time.NewTicker(announcementCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			events, err := b.eventService.TakeDueOpenings(ctx, now)
+			if err != nil {
+				log.Warn().Msgf("Failed to get the due registration openings: %s.", err)
+			}
+			for _, event := range events {
+				l := b.localizer(ctx, event.ChatId, nil)
+				b.sender.enqueue(ctx, event.ChatId, tgbotapi.NewMessage(event.ChatId, l.T("registration.announcement")))
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package tgbot
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// remindKickoffs reminds the chats of the kickoff at the hours of the reminders setting until the context
+// is cancelled.
+func (b *TgBot) remindKickoffs(ctx context.Context) {
+	ticker := time.NewTicker(announcementCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			events, err := b.eventService.TakeDueReminders(ctx, now)
+			if err != nil {
+				log.Warn().Msgf("Failed to get the due reminders: %s.", err)
+			}
+			for _, event := range events {
+				b.remindKickoff(ctx, event)
+			}
+		}
+	}
+}
+
+func (b *TgBot) remindKickoff(ctx context.Context, event *model.Event) {
+	settings, err := b.eventService.GetSettings(ctx, event.ChatId)
+	if err != nil {
+		log.Warn().Msgf("Failed to get settings of the chat %d: %s.", event.ChatId, err)
+		return
+	}
+	l := b.localizer(ctx, event.ChatId, nil)
+	text := l.T("reminder.kickoff", event.Title, model.FormatTime(event.StartsAt, settings.Location()))
+	b.sender.enqueue(ctx, event.ChatId, tgbotapi.NewMessage(event.ChatId, text))
+}
@@ -0,0 +1,227 @@
+package tgbot
+
+import (
+	"context"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+// seenUsers remembers the users recorded by this instance to store them again only when they change.
+type seenUsers struct {
+	sync.Mutex
+	users map[string]model.ChatUser
+}
+
+// recordUser stores the sender of the message, so the sender can be found by @username.
+func (b *TgBot) recordUser(ctx context.Context, message *tgbotapi.Message) {
+	if message.From == nil || message.From.IsBot {
+		return
+	}
+	user := newChatUser(message.Chat.ID, message.From)
+	user.ChatTitle = message.Chat.Title
+	key := fmt.Sprintf("%d-%d", user.ChatId, user.UserId)
+	b.seen.Lock()
+	prev, ok := b.seen.users[key]
+	b.seen.Unlock()
+	if ok && prev == user {
+		return
+	}
+	if err := b.eventService.RecordChatUser(ctx, user); err != nil {
+		log.Warn().Msgf("Failed to record the user %d of the chat %d: %s.", user.UserId, user.ChatId, err)
+		return
+	}
+	b.seen.Lock()
+	b.seen.users[key] = user
+	b.seen.Unlock()
+}
+
+// resolveTarget finds the user the command is about: a text mention, an @username mention or the author
+// of the replied message. It returns the arguments without the mention, the user is looked up in the chat.
+func (b *TgBot) resolveTarget(ctx context.Context, chatId int64, message *tgbotapi.Message) (*model.ChatUser, string, error) {
+	arguments := message.CommandArguments()
+	for _, entity := range message.Entities {
+		switch {
+		case entity.Type == "text_mention" && entity.User != nil:
+			user := newChatUser(chatId, entity.User)
+			return &user, removeMention(arguments, entityText(message.Text, entity)), nil
+		case entity.IsMention():
+			mention := entityText(message.Text, entity)
+			user, err := b.eventService.FindChatUser(ctx, chatId, mention)
+			if err != nil {
+				return nil, arguments, err
+			}
+			if user == nil {
+				// the user wasn't seen in the chat yet, it's matched by the username later
+				user = &model.ChatUser{ChatId: chatId, Username: model.NormalizeUsername(mention), Name: mention}
+			}
+			return user, removeMention(arguments, mention), nil
+		}
+	}
+	if reply := message.ReplyToMessage; reply != nil && reply.From != nil && !reply.From.IsBot {
+		user := newChatUser(chatId, reply.From)
+		return &user, arguments, nil
+	}
+	return nil, arguments, nil
+}
+
+// addsMember checks if /i is about a chat member: the member is mentioned or the command replies
+// to a message of the member. A name without a mention is a guest.
+func addsMember(message *tgbotapi.Message) bool {
+	for _, entity := range message.Entities {
+		if entity.IsMention() || entity.Type == "text_mention" {
+			return true
+		}
+	}
+	reply := message.ReplyToMessage
+	return reply != nil && reply.From != nil && !reply.From.IsBot && !hasArguments(message)
+}
+
+// memberToAdd resolves the chat member added with /i, the reply text and the outcome explain why when there is none.
+// A user the bot hasn't seen yet is added as a guest of the sender named by the @username, the user takes the guest
+// over when joining.
+func (b *TgBot) memberToAdd(ctx context.Context, chatId int64, update tgbotapi.Update, self *model.Participant, l i18n.Localizer) (*model.Participant, string, string) {
+	target, _, err := b.resolveTarget(ctx, chatId, update.Message)
+	if err != nil || target == nil {
+		return nil, l.T("participant.add.failed", update.Message.CommandArguments()), metrics.OutcomeError
+	}
+	if target.UserId == 0 {
+		return &model.Participant{Name: target.Mention(), InvitedBy: self}, "", metrics.OutcomeSuccess
+	}
+	userId := target.UserId
+	return &model.Participant{
+		Name:       target.Name,
+		Username:   target.Username,
+		TelegramId: &userId,
+	}, "", metrics.OutcomeSuccess
+}
+
+// getRole returns the role of the user in the chat, failing to get it means no role.
+func (b *TgBot) getRole(ctx context.Context, chatId int64, user *tgbotapi.User) model.Role {
+	role, err := b.eventService.GetRole(ctx, chatId, newChatUser(chatId, user))
+	if err != nil {
+		log.Error().Msgf("Failed to get the role of %d in the chat %d: %s.", user.ID, chatId, err)
+		return ""
+	}
+	return role
+}
+
+func (b *TgBot) hasPermissionToManageRoles(ctx context.Context, user *tgbotapi.User, chatId int64) (bool, error) {
+	if b.getRole(ctx, chatId, user).CanManageRoles() {
+		return true, nil
+	}
+	return b.admins.isAdmin(ctx, chatId, user.ID)
+}
+
+func (b *TgBot) grantRole(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	hasPermission, err := b.hasPermissionToManageRoles(ctx, update.Message.From, chatId)
+	if err != nil {
+		return l.T("permissions.failed"), metrics.OutcomeError
+	}
+	if !hasPermission {
+		return l.T("role.grant.denied"), metrics.OutcomePermissionDenied
+	}
+	target, arguments, err := b.resolveTarget(ctx, chatId, update.Message)
+	if err != nil {
+		return l.T("role.grant.failed"), metrics.OutcomeError
+	}
+	role, ok := model.ParseRole(arguments)
+	if target == nil || !ok {
+		return l.T("role.grant.usage"), metrics.OutcomeError
+	}
+	_, err = b.eventService.GrantRole(ctx, chatId, *target, role, newChatUser(chatId, update.Message.From))
+	if isDuplicate(update, err) {
+		return "", metrics.OutcomeSuccess
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to grant %s to %s in the chat %d: %s.", role, target.Mention(), chatId, err)
+		return l.T("role.grant.failed"), metrics.OutcomeError
+	}
+	return l.T("role.granted", target.Mention(), role), metrics.OutcomeSuccess
+}
+
+func (b *TgBot) revokeRole(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	hasPermission, err := b.hasPermissionToManageRoles(ctx, update.Message.From, chatId)
+	if err != nil {
+		return l.T("permissions.failed"), metrics.OutcomeError
+	}
+	if !hasPermission {
+		return l.T("role.revoke.denied"), metrics.OutcomePermissionDenied
+	}
+	target, _, err := b.resolveTarget(ctx, chatId, update.Message)
+	if err != nil {
+		return l.T("role.revoke.failed"), metrics.OutcomeError
+	}
+	if target == nil {
+		return l.T("role.revoke.usage"), metrics.OutcomeError
+	}
+	revoked, err := b.eventService.RevokeRole(ctx, chatId, *target, newChatUser(chatId, update.Message.From))
+	if isDuplicate(update, err) {
+		return "", metrics.OutcomeSuccess
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to revoke the role of %s in the chat %d: %s.", target.Mention(), chatId, err)
+		return l.T("role.revoke.failed"), metrics.OutcomeError
+	}
+	if revoked == nil {
+		return l.T("role.none", target.Mention()), metrics.OutcomeSuccess
+	}
+	return l.T("role.revoked", target.Mention(), revoked.Role), metrics.OutcomeSuccess
+}
+
+func (b *TgBot) listRoles(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	roles, err := b.eventService.GetRoles(ctx, chatId)
+	if err != nil {
+		return l.T("roles.get.failed"), metrics.OutcomeError
+	}
+	if len(roles) == 0 {
+		return l.T("roles.empty"), metrics.OutcomeSuccess
+	}
+	var sb strings.Builder
+	sb.WriteString(l.N("roles.list", len(roles)))
+	for _, role := range roles {
+		sb.WriteString(fmt.Sprintf("\n%s - %s", role.Mention(), role.Role))
+	}
+	return sb.String(), metrics.OutcomeSuccess
+}
+
+func newChatUser(chatId int64, user *tgbotapi.User) model.ChatUser {
+	return model.ChatUser{
+		ChatId:   chatId,
+		UserId:   user.ID,
+		Username: model.NormalizeUsername(user.UserName),
+		Name:     displayName(user),
+	}
+}
+
+func displayName(user *tgbotapi.User) string {
+	if len(strings.TrimSpace(user.UserName)) > 0 {
+		return user.UserName
+	} else if len(strings.TrimSpace(user.FirstName)) > 0 {
+		return strings.TrimSpace(fmt.Sprintf("%s %s", user.FirstName, user.LastName))
+	}
+	return strconv.FormatInt(user.ID, 10)
+}
+
+// entityText returns the text of the entity, entity offsets are in UTF-16 code units.
+func entityText(text string, entity tgbotapi.MessageEntity) string {
+	encoded := utf16.Encode([]rune(text))
+	if entity.Offset < 0 || entity.Offset+entity.Length > len(encoded) {
+		return ""
+	}
+	return string(utf16.Decode(encoded[entity.Offset : entity.Offset+entity.Length]))
+}
+
+func removeMention(arguments string, mention string) string {
+	return strings.TrimSpace(strings.Replace(arguments, mention, "", 1))
+}
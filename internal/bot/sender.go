@@ -0,0 +1,316 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/metrics"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Telegram limits, see https://core.telegram.org/bots/faq#my-bot-is-hitting-limits-how-do-i-avoid-this
+const (
+	globalMessagesPerSecond = 30
+	privateChatMessageEvery = time.Second
+	groupChatMessageEvery   = 3 * time.Second // 20 messages per minute
+
+	senderWorkers     = 4
+	senderQueueSize   = 256
+	maxSendAttempts   = 5
+	initialBackoff    = time.Second
+	maxBackoff        = 30 * time.Second
+	chatLimiterMaxAge = time.Hour
+)
+
+type outgoing struct {
+	ctx    context.Context
+	chatId int64
+	msg    tgbotapi.Chattable
+	// attempts counts the failed attempts, backoff is the delay of the next retry of a server or network error.
+	attempts int
+	backoff  time.Duration
+	// notBefore is when a failed message is retried or a message over the limit of its chat is sent.
+	notBefore time.Time
+}
+
+// sender delivers the messages asynchronously respecting Telegram rate limits. Messages of a chat are sent
+// in order. Requests limited by Telegram are retried after the time it asks for, server and network errors are
+// retried with exponential backoff, messages which can't be delivered are logged as dead letters. A message
+// waiting for a retry or for the limit of its chat holds back the later messages of its chat only, the other
+// chats of the worker go on.
+type sender struct {
+	queues  []chan outgoing
+	send    func(ctx context.Context, msg tgbotapi.Chattable) error
+	global  *rate.Limiter
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	backoff time.Duration
+
+	// mu guards stopped, enqueue holds it for reading while registering in sending. The queues are closed
+	// once the registered sends are over, so nothing is sent to a closed queue.
+	mu        sync.RWMutex
+	stopped   bool
+	sending   sync.WaitGroup
+	closeOnce sync.Once
+
+	limitersMu sync.Mutex
+	limiters   map[int64]*chatLimiter
+}
+
+type chatLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+func newSender(send func(ctx context.Context, msg tgbotapi.Chattable) error) *sender {
+	queues := make([]chan outgoing, senderWorkers)
+	for i := range queues {
+		queues[i] = make(chan outgoing, senderQueueSize)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &sender{
+		queues:   queues,
+		send:     send,
+		global:   rate.NewLimiter(globalMessagesPerSecond, globalMessagesPerSecond),
+		ctx:      ctx,
+		cancel:   cancel,
+		backoff:  initialBackoff,
+		limiters: make(map[int64]*chatLimiter),
+	}
+}
+
+func (s *sender) start() {
+	for _, queue := range s.queues {
+		s.wg.Add(1)
+		go s.work(queue)
+	}
+}
+
+// enqueue schedules the message to the chat. The context is used for tracing only, the message is sent
+// after the caller returns. It blocks while the queue is full until sending is cancelled by stop.
+func (s *sender) enqueue(ctx context.Context, chatId int64, msg tgbotapi.Chattable) {
+	s.mu.RLock()
+	if s.stopped {
+		s.mu.RUnlock()
+		deadLetter(chatId, msg, errors.New("sender is stopped"))
+		return
+	}
+	s.sending.Add(1)
+	s.mu.RUnlock()
+	defer s.sending.Done()
+	select {
+	case s.queues[shardIdx(chatId, len(s.queues))] <- outgoing{ctx: context.WithoutCancel(ctx), chatId: chatId, msg: msg, backoff: s.backoff}:
+	case <-s.ctx.Done():
+		deadLetter(chatId, msg, s.ctx.Err())
+	}
+}
+
+// stop waits for the queued messages to be sent. When the context expires first, sending is cancelled
+// and the remaining messages are logged as dead letters.
+func (s *sender) stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	sent := make(chan struct{})
+	go func() {
+		s.sending.Wait()
+		s.closeOnce.Do(func() {
+			for _, queue := range s.queues {
+				close(queue)
+			}
+		})
+		s.wg.Wait()
+		close(sent)
+	}()
+	select {
+	case <-sent:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-sent
+		return ctx.Err()
+	}
+}
+
+func (s *sender) queueDepth() int {
+	depth := 0
+	for _, queue := range s.queues {
+		depth += len(queue)
+	}
+	return depth
+}
+
+// work sends the messages of the queue, the messages to retry wait in waiting by chat with the later messages
+// of the chat queued after them. It returns once the queue is closed and nothing waits.
+func (s *sender) work(queue chan outgoing) {
+	defer s.wg.Done()
+	waiting := make(map[int64][]outgoing)
+	done := s.ctx.Done()
+	for queue != nil || len(waiting) > 0 {
+		var retry <-chan time.Time
+		if next, ok := nextRetry(waiting); ok {
+			retry = time.After(time.Until(next))
+		}
+		select {
+		case out, ok := <-queue:
+			if !ok {
+				queue = nil
+				continue
+			}
+			if pending, ok := waiting[out.chatId]; ok {
+				waiting[out.chatId] = append(pending, out)
+			} else if !s.deliver(&out) {
+				waiting[out.chatId] = []outgoing{out}
+			}
+		case <-retry:
+			now := time.Now()
+			for chatId, pending := range waiting {
+				if !pending[0].notBefore.After(now) {
+					s.resume(waiting, chatId)
+				}
+			}
+		case <-done:
+			for chatId, pending := range waiting {
+				for _, out := range pending {
+					deadLetter(chatId, out.msg, s.ctx.Err())
+				}
+				delete(waiting, chatId)
+			}
+			// the rest of the queue is logged as dead letters by deliver
+			done = nil
+		}
+	}
+}
+
+// deliver tries to send the message, it returns false if the message has to be retried at notBefore.
+// Messages which can't be delivered are logged as dead letters.
+func (s *sender) deliver(out *outgoing) bool {
+	// sending was cancelled by stop
+	if err := s.ctx.Err(); err != nil {
+		deadLetter(out.chatId, out.msg, err)
+		return true
+	}
+	if delay := s.reserve(out.chatId); delay > 0 {
+		out.notBefore = time.Now().Add(delay)
+		return false
+	}
+	if err := s.global.Wait(s.ctx); err != nil {
+		deadLetter(out.chatId, out.msg, err)
+		return true
+	}
+	err := s.send(out.ctx, out.msg)
+	if err == nil {
+		return true
+	}
+	out.attempts++
+	delay, retryable := retryDelay(err, out.backoff)
+	if !retryable || out.attempts == maxSendAttempts {
+		deadLetter(out.chatId, out.msg, err)
+		return true
+	}
+	log.Warn().Msgf("Failed to send a message to the chat %d, attempt %d, retrying in %s: %s.", out.chatId, out.attempts, delay, err)
+	metrics.ObserveMessageRetry()
+	out.notBefore = time.Now().Add(delay)
+	out.backoff = min(out.backoff*2, maxBackoff)
+	return false
+}
+
+// resume sends the waiting messages of the chat in order until one has to be retried again.
+func (s *sender) resume(waiting map[int64][]outgoing, chatId int64) {
+	pending := waiting[chatId]
+	for len(pending) > 0 {
+		if !s.deliver(&pending[0]) {
+			waiting[chatId] = pending
+			return
+		}
+		pending = pending[1:]
+	}
+	delete(waiting, chatId)
+}
+
+// nextRetry returns the earliest time a waiting message is retried.
+func nextRetry(waiting map[int64][]outgoing) (time.Time, bool) {
+	var next time.Time
+	for _, pending := range waiting {
+		if next.IsZero() || pending[0].notBefore.Before(next) {
+			next = pending[0].notBefore
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// reserve takes a message of the limit of the chat, it returns how long to wait when the limit doesn't allow
+// sending now and the message isn't counted then. Waiting for the global limit is short, it's done in place.
+func (s *sender) reserve(chatId int64) time.Duration {
+	r := s.chatLimiter(chatId).Reserve()
+	delay := r.Delay()
+	if delay > 0 {
+		r.Cancel()
+	}
+	return delay
+}
+
+func (s *sender) chatLimiter(chatId int64) *rate.Limiter {
+	s.limitersMu.Lock()
+	defer s.limitersMu.Unlock()
+	now := time.Now()
+	l, ok := s.limiters[chatId]
+	if !ok {
+		every := privateChatMessageEvery
+		if chatId < 0 {
+			every = groupChatMessageEvery
+		}
+		// a short burst keeps replies to a couple of quick commands instant
+		l = &chatLimiter{limiter: rate.NewLimiter(rate.Every(every), 3), lastUsed: now}
+		s.limiters[chatId] = l
+		s.evictLimiters(now)
+	}
+	l.lastUsed = now
+	return l.limiter
+}
+
+// evictLimiters forgets the limiters of the chats without messages for a while.
+func (s *sender) evictLimiters(now time.Time) {
+	for chatId, l := range s.limiters {
+		if now.Sub(l.lastUsed) > chatLimiterMaxAge {
+			delete(s.limiters, chatId)
+		}
+	}
+}
+
+// retryDelay decides if the failed request is worth retrying and when.
+func retryDelay(err error, backoff time.Duration) (time.Duration, bool) {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		if tgErr.RetryAfter > 0 {
+			return time.Duration(tgErr.RetryAfter) * time.Second, true
+		}
+		return backoff, tgErr.Code >= http.StatusInternalServerError
+	}
+	// network errors and broken responses
+	return backoff, true
+}
+
+func deadLetter(chatId int64, msg tgbotapi.Chattable, err error) {
+	metrics.ObserveDeadLetter()
+	event := log.Error().Bool("dead_letter", true).Int64("chat_id", chatId)
+	if m, ok := msg.(tgbotapi.MessageConfig); ok {
+		event = event.Str("text", m.Text)
+	}
+	event.Msgf("Failed to deliver a message: %s.", err)
+}
+
+func shardIdx(key int64, shards int) int {
+	idx := key % int64(shards)
+	if idx < 0 {
+		idx = -idx
+	}
+	return int(idx)
+}
@@ -0,0 +1,165 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSender_RetriesServerErrors(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	s := newSender(func(ctx context.Context, msg tgbotapi.Chattable) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			return &tgbotapi.Error{Code: 502, Message: "Bad Gateway"}
+		}
+		return nil
+	})
+	s.backoff = time.Millisecond
+	s.start()
+	s.enqueue(context.Background(), 1, tgbotapi.NewMessage(1, "Event created."))
+	assert.NoError(t, s.stop(context.Background()))
+
+	assert.Equal(t, 3, attempts)
+}
+
+func TestSender_DoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	s := newSender(func(ctx context.Context, msg tgbotapi.Chattable) error {
+		attempts++
+		return &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+	})
+	s.backoff = time.Millisecond
+	s.start()
+	s.enqueue(context.Background(), 1, tgbotapi.NewMessage(1, "Event created."))
+	assert.NoError(t, s.stop(context.Background()))
+
+	assert.Equal(t, 1, attempts)
+}
+
+func TestSender_KeepsOrderWithinChat(t *testing.T) {
+	var sent []string
+	s := newSender(func(ctx context.Context, msg tgbotapi.Chattable) error {
+		sent = append(sent, msg.(tgbotapi.MessageConfig).Text)
+		return nil
+	})
+	s.start()
+	s.enqueue(context.Background(), 1, tgbotapi.NewMessage(1, "first"))
+	s.enqueue(context.Background(), 1, tgbotapi.NewMessage(1, "second"))
+	s.enqueue(context.Background(), 1, tgbotapi.NewMessage(1, "third"))
+	assert.NoError(t, s.stop(context.Background()))
+
+	assert.Equal(t, []string{"first", "second", "third"}, sent)
+}
+
+func TestSender_RetryDoesNotHoldBackOtherChats(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	limited := false
+	s := newSender(func(ctx context.Context, msg tgbotapi.Chattable) error {
+		mu.Lock()
+		defer mu.Unlock()
+		text := msg.(tgbotapi.MessageConfig).Text
+		if text == "limited" && !limited {
+			limited = true
+			return &tgbotapi.Error{Code: 429, Message: "Too Many Requests: retry after 1", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 1}}
+		}
+		sent = append(sent, text)
+		return nil
+	})
+	s.start()
+	// both chats are handled by the same worker
+	s.enqueue(context.Background(), 1, tgbotapi.NewMessage(1, "limited"))
+	s.enqueue(context.Background(), 1, tgbotapi.NewMessage(1, "after limited"))
+	s.enqueue(context.Background(), 1+senderWorkers, tgbotapi.NewMessage(1+senderWorkers, "other chat"))
+	assert.NoError(t, s.stop(context.Background()))
+
+	assert.Equal(t, []string{"other chat", "limited", "after limited"}, sent)
+}
+
+func TestSender_ChatLimitDoesNotHoldBackOtherChats(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	s := newSender(func(ctx context.Context, msg tgbotapi.Chattable) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, msg.(tgbotapi.MessageConfig).Text)
+		return nil
+	})
+	s.start()
+	// both groups are handled by the same worker, the burst of a group is 3 messages
+	for _, text := range []string{"first", "second", "third", "limited"} {
+		s.enqueue(context.Background(), -1, tgbotapi.NewMessage(-1, text))
+	}
+	s.enqueue(context.Background(), -1-senderWorkers, tgbotapi.NewMessage(-1-senderWorkers, "other chat"))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent) == 4
+	}, time.Second, time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.stop(ctx), context.DeadlineExceeded)
+
+	assert.Equal(t, []string{"first", "second", "third", "other chat"}, sent)
+}
+
+func TestSender_StopCancelsBlockedEnqueue(t *testing.T) {
+	release := make(chan struct{})
+	s := newSender(func(ctx context.Context, msg tgbotapi.Chattable) error {
+		<-release
+		return nil
+	})
+	s.start()
+	// one message is being sent, the queue is full and the last one waits for a place
+	enqueued := make(chan struct{})
+	go func() {
+		for i := 0; i < senderQueueSize+2; i++ {
+			s.enqueue(context.Background(), 1, tgbotapi.NewMessage(1, "queued"))
+		}
+		close(enqueued)
+	}()
+	assert.Eventually(t, func() bool { return s.queueDepth() == senderQueueSize }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	stopped := make(chan error)
+	go func() {
+		stopped <- s.stop(ctx)
+	}()
+	select {
+	case <-enqueued:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Enqueue kept waiting after stop expired")
+	}
+	close(release)
+	assert.ErrorIs(t, <-stopped, context.DeadlineExceeded)
+}
+
+func TestRetryDelay(t *testing.T) {
+	delay, retryable := retryDelay(&tgbotapi.Error{
+		Code:               429,
+		Message:            "Too Many Requests: retry after 7",
+		ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7},
+	}, time.Second)
+	assert.True(t, retryable)
+	assert.Equal(t, 7*time.Second, delay)
+
+	delay, retryable = retryDelay(&tgbotapi.Error{Code: 500, Message: "Internal Server Error"}, 2*time.Second)
+	assert.True(t, retryable)
+	assert.Equal(t, 2*time.Second, delay)
+
+	_, retryable = retryDelay(&tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}, time.Second)
+	assert.False(t, retryable)
+
+	_, retryable = retryDelay(errors.New("connection reset by peer"), time.Second)
+	assert.True(t, retryable)
+}
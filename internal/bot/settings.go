@@ -0,0 +1,183 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// settingsCallbackPrefix marks the buttons of the settings menu, the data is settings:<key>:<value>.
+const settingsCallbackPrefix = "settings:"
+
+func (b *TgBot) hasPermissionToManageSettings(ctx context.Context, user *tgbotapi.User, chatId int64) (bool, error) {
+	if b.getRole(ctx, chatId, user).CanManageSettings() {
+		return true, nil
+	}
+	return b.admins.isAdmin(ctx, chatId, user.ID)
+}
+
+// settings shows the settings of the chat with /settings and a menu to change them, /settings key value changes
+// a setting. Only changing a setting, with the command or the menu, requires the permission.
+func (b *TgBot) settings(ctx context.Context, update tgbotapi.Update, l i18n.Localizer, msg *tgbotapi.MessageConfig) string {
+	chatId := update.Message.Chat.ID
+	if !hasArguments(update.Message) {
+		settings, err := b.eventService.GetSettings(ctx, chatId)
+		if err != nil {
+			msg.Text = l.T("settings.get.failed")
+			return metrics.OutcomeError
+		}
+		msg.Text = formatSettings(l, settings)
+		msg.ReplyMarkup = settingsKeyboard(settings)
+		return metrics.OutcomeSuccess
+	}
+	hasPermission, err := b.hasPermissionToManageSettings(ctx, update.Message.From, chatId)
+	if err != nil {
+		msg.Text = l.T("permissions.failed")
+		return metrics.OutcomeError
+	}
+	if !hasPermission {
+		msg.Text = l.T("settings.change.denied")
+		return metrics.OutcomePermissionDenied
+	}
+	key, value := cutArgument(update.Message.CommandArguments())
+	key = model.NormalizeSettingKey(key)
+	settings, err := b.eventService.UpdateSetting(ctx, chatId, key, value, newChatUser(chatId, update.Message.From))
+	if isDuplicate(update, err) {
+		return metrics.OutcomeSuccess
+	}
+	if errors.Is(err, model.ErrInvalidSetting) {
+		if slices.Contains(model.SettingKeys, key) {
+			msg.Text = l.T("settings.invalid", key, l.T("settings.hint."+key))
+		} else {
+			msg.Text = l.T("settings.unknown", key, strings.Join(model.SettingKeys, ", "))
+		}
+		return metrics.OutcomeError
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to change the setting %s of the chat %d: %s.", key, chatId, err)
+		msg.Text = l.T("settings.change.failed")
+		return metrics.OutcomeError
+	}
+	if key == model.SettingLanguage {
+		l = localize(chatId, update.Message.From, settings)
+	}
+	msg.Text = l.T("settings.changed", key, settings.Get(key))
+	return metrics.OutcomeSuccess
+}
+
+// handleSettingsCallback applies a button of the settings menu and updates the menu message.
+func (b *TgBot) handleSettingsCallback(ctx context.Context, update tgbotapi.Update) (outcome string) {
+	query := update.CallbackQuery
+	if query.Message == nil || !strings.HasPrefix(query.Data, settingsCallbackPrefix) {
+		b.answerCallback(ctx, query, "")
+		return
+	}
+	chatId := query.Message.Chat.ID
+	key, value, _ := strings.Cut(strings.TrimPrefix(query.Data, settingsCallbackPrefix), ":")
+
+	ctx, span := tracer.Start(ctx, "callback", trace.WithAttributes(
+		attribute.Int("update.id", update.UpdateID),
+		attribute.Int64("chat.id", chatId),
+		attribute.String("setting", key),
+	))
+	defer span.End()
+	outcome = metrics.OutcomeSuccess
+	defer func(start time.Time) {
+		metrics.ObserveCommand("settings", outcome, start)
+		span.SetAttributes(attribute.String("outcome", outcome))
+	}(time.Now())
+
+	l := b.localizer(ctx, chatId, query.From)
+	hasPermission, err := b.hasPermissionToManageSettings(ctx, query.From, chatId)
+	if err != nil {
+		outcome = metrics.OutcomeError
+		b.answerCallback(ctx, query, l.T("permissions.failed"))
+		return
+	}
+	if !hasPermission {
+		outcome = metrics.OutcomePermissionDenied
+		b.answerCallback(ctx, query, l.T("settings.change.denied"))
+		return
+	}
+	settings, err := b.eventService.UpdateSetting(ctx, chatId, key, value, newChatUser(chatId, query.From))
+	if isDuplicate(update, err) {
+		b.answerCallback(ctx, query, "")
+		return
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to change the setting %s of the chat %d: %s.", key, chatId, err)
+		outcome = metrics.OutcomeError
+		b.answerCallback(ctx, query, l.T("settings.change.failed"))
+		return
+	}
+	l = localize(chatId, query.From, settings)
+	b.answerCallback(ctx, query, l.T("settings.changed", key, settings.Get(key)))
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatId, query.Message.MessageID, formatSettings(l, settings), settingsKeyboard(settings))
+	b.sender.enqueue(ctx, chatId, edit)
+	return
+}
+
+// answerCallback stops the loading indicator of the button, it's not queued to answer before Telegram gives up.
+func (b *TgBot) answerCallback(ctx context.Context, query *tgbotapi.CallbackQuery, text string) {
+	if _, err := b.api(ctx).Request(tgbotapi.NewCallback(query.ID, text)); err != nil {
+		log.Warn().Msgf("Failed to answer the callback query %s: %s.", query.ID, err)
+	}
+}
+
+func formatSettings(l i18n.Localizer, settings *model.ChatSettings) string {
+	var sb strings.Builder
+	sb.WriteString(l.T("settings.title"))
+	for _, key := range model.SettingKeys {
+		sb.WriteString(fmt.Sprintf("\n%s: %s", key, settings.Get(key)))
+	}
+	sb.WriteString("\n\n" + l.T("settings.help"))
+	return sb.String()
+}
+
+// settingsKeyboard has a button per setting with a few choices, each click switches to the next value.
+func settingsKeyboard(settings *model.ChatSettings) tgbotapi.InlineKeyboardMarkup {
+	button := func(key string, label string, value string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(label, settingsCallbackPrefix+key+":"+value)
+	}
+	toggle := func(key string, enabled bool) tgbotapi.InlineKeyboardButton {
+		next := "on"
+		if enabled {
+			next = "off"
+		}
+		return button(key, fmt.Sprintf("%s: %s", key, settings.Get(key)), next)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			button(model.SettingLanguage, "language: "+settings.Language, nextOf(model.SupportedLanguages, settings.Language)),
+			button(model.SettingRemove, "remove: "+string(settings.RemoveOthers), string(nextOf(model.RemovePolicies, settings.RemoveOthers))),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			toggle(model.SettingGuests, settings.GuestsAllowed),
+			toggle(model.SettingWaitlist, settings.Waitlist),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			button(model.SettingCapacity, "capacity -1", strconv.Itoa(max(settings.DefaultCapacity-1, 0))),
+			button(model.SettingCapacity, "capacity +1", strconv.Itoa(settings.DefaultCapacity+1)),
+		),
+	)
+}
+
+func nextOf[T comparable](values []T, current T) T {
+	for i, v := range values {
+		if v == current {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
@@ -0,0 +1,80 @@
+package tgbot
+
+import (
+	"context"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHistorySize is the number of events /history shows without an argument.
+const defaultHistorySize = 10
+
+// cancelEvent handles /cancel, /cancel rain also tells the participants the reason.
+func (b *TgBot) cancelEvent(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	reason := strings.TrimSpace(update.Message.CommandArguments())
+	event, err := b.eventService.CancelEvent(ctx, chatId, reason, newChatUser(chatId, update.Message.From))
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	switch {
+	case isDuplicate(update, err):
+		return "", metrics.OutcomeSuccess
+	case err != nil:
+		log.Error().Msgf("Failed to cancel the event of the chat %d: %s.", chatId, err)
+		return l.T("cancel.failed"), metrics.OutcomeError
+	}
+	return l.T("cancel.done", event.Title), metrics.OutcomeSuccess
+}
+
+// history lists the latest events of the chat with their status with /history, /history 20 shows more of them.
+func (b *TgBot) history(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	size := defaultHistorySize
+	if hasArguments(update.Message) {
+		var err error
+		size, err = strconv.Atoi(strings.TrimSpace(update.Message.CommandArguments()))
+		if err != nil || size <= 0 {
+			return l.T("history.usage"), metrics.OutcomeError
+		}
+	}
+	settings, err := b.eventService.GetSettings(ctx, chatId)
+	if err != nil {
+		log.Error().Msgf("Failed to get settings of the chat %d: %s.", chatId, err)
+		return l.T("history.failed"), metrics.OutcomeError
+	}
+	events, err := b.eventService.History(ctx, chatId, size)
+	if err != nil {
+		log.Error().Msgf("Failed to get the events of the chat %d: %s.", chatId, err)
+		return l.T("history.failed"), metrics.OutcomeError
+	}
+	if len(events) == 0 {
+		return l.T("history.empty"), metrics.OutcomeSuccess
+	}
+	return formatHistory(l, events, settings.Location()), metrics.OutcomeSuccess
+}
+
+// formatHistory lists the events, the newest first, dated by the kickoff or by the creation without one.
+func formatHistory(l i18n.Localizer, events []*model.Event, loc *time.Location) string {
+	var sb strings.Builder
+	sb.WriteString(l.T("history.title"))
+	for _, event := range events {
+		date := event.StartsAt
+		if date.IsZero() {
+			date = event.Created
+		}
+		status := l.T("history.status." + string(event.Status))
+		if event.CancelReason != "" {
+			status += " (" + event.CancelReason + ")"
+		}
+		sb.WriteString("\n")
+		sb.WriteString(l.T("history.event", date.In(loc).Format("02.01.2006"), event.Title, status))
+	}
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package tgbot
+
+import (
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFormatHistory_StatusesAndReasons(t *testing.T) {
+	l := i18n.For("en")
+	created := time.Date(2024, time.May, 1, 18, 0, 0, 0, time.UTC)
+	cancelled := &model.Event{Title: "Football", Created: created, StartsAt: created.AddDate(0, 0, 7)}
+	cancelled.Cancel("rain")
+	completed := &model.Event{Title: "Chess", Created: created, Status: model.StatusCompleted}
+
+	text := formatHistory(l, []*model.Event{cancelled, completed}, time.UTC)
+
+	assert.Equal(t, "Recent events:\n08.05.2024 Football: cancelled (rain)\n01.05.2024 Chess: completed", text)
+}
+
+func TestFormatHistory_AllStatusesLocalized(t *testing.T) {
+	statuses := []model.Status{model.StatusDraft, model.StatusOpen, model.StatusClosed, model.StatusCancelled, model.StatusCompleted}
+	for _, lang := range i18n.Languages() {
+		for _, status := range statuses {
+			text := formatHistory(i18n.For(lang), []*model.Event{{Title: "Football", Status: status}}, time.UTC)
+			assert.NotContainsf(t, text, "history.", "Missing %s translation of %s", lang, status)
+			assert.NotContainsf(t, text, "%!", "Bad %s format of %s", lang, status)
+		}
+	}
+}
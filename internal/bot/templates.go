@@ -0,0 +1,391 @@
+package tgbot
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"fmt"
+	"github.com/Masterminds/sprig/v3"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"html"
+	templating "html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxTemplateSize limits the source of a custom template in bytes.
+	maxTemplateSize = 8 << 10
+	// maxRenderedLength is the Telegram limit of a message in characters.
+	maxRenderedLength = 4096
+	// maxRangeDepth limits nested ranges of custom templates, so rendering takes time proportional
+	// to the square of the participants at most.
+	maxRangeDepth = 2
+)
+
+var errRenderedTooLong = fmt.Errorf("the rendered event is longer than %d characters", maxRenderedLength)
+
+// deniedTemplateFuncs are the sprig functions custom templates can't use on top of the non-hermetic ones,
+// they allocate memory or burn CPU proportional to their arguments.
+var deniedTemplateFuncs = []string{
+	"repeat", "indent", "nindent", "until", "untilStep", "seq",
+	"bcrypt", "htpasswd", "derivePassword", "genPrivateKey", "buildCustomCert", "genCA", "genCAWithKey",
+	"genSelfSignedCert", "genSelfSignedCertWithKey", "genSignedCert", "genSignedCertWithKey", "encryptAES", "decryptAES",
+}
+
+// renderEvent renders the event with the custom template of the chat. Without one, or when it fails, the template
+// of the language is used, the English one when there is none.
+func (b *TgBot) renderEvent(ctx context.Context, chatId int64, l i18n.Localizer, event Event) string {
+	custom, err := b.eventService.GetEventTemplate(ctx, chatId)
+	if err != nil {
+		log.Warn().Msgf("Failed to get the template of the chat %d, using the default one: %s.", chatId, err)
+	} else if custom != nil {
+		text, err := renderCustom(custom.Source, l, event)
+		if err == nil {
+			return text
+		}
+		log.Warn().Msgf("Failed to render the event %s with the template of the chat, using the default one: %s.", event.Id, err)
+	}
+	return b.renderDefault(l, event)
+}
+
+func (b *TgBot) renderDefault(l i18n.Localizer, event Event) string {
+	t, ok := b.eventTemplates[l.Lang()]
+	if !ok {
+		t = b.eventTemplates[i18n.DefaultLanguage]
+	}
+	var doc bytes.Buffer
+	err := t.ExecuteTemplate(&doc, "event", event)
+	if err != nil {
+		log.Error().Msgf("Failed to render the event %s: %s.", event.Id, err)
+	}
+	return doc.String()
+}
+
+// templateFiles are the event templates, event.gohtml is English, event.<language>.gohtml are the translations.
+//
+//go:embed event*.gohtml
+var templateFiles embed.FS
+
+// getTemplates parses the event templates by language. Templates can pick the plural form of a word with
+// plural, e.g. {{plural 5 "participant" "participants"}}.
+func getTemplates() (map[string]*templating.Template, error) {
+	files, err := fs.Glob(templateFiles, "event*.gohtml")
+	if err != nil {
+		return nil, err
+	}
+	templates := make(map[string]*templating.Template)
+	for _, file := range files {
+		lang := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSuffix(file, ".gohtml"), "event"), ".")
+		if lang == "" {
+			lang = i18n.DefaultLanguage
+		}
+		content, err := templateFiles.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		t, err := templating.New("event").Funcs(templateFuncs(lang)).Parse(string(content))
+		if err != nil {
+			log.Error().Msgf("Failed to get the template %s: %s.", file, err)
+			return nil, err
+		}
+		templates[lang] = t
+	}
+	if _, ok := templates[i18n.DefaultLanguage]; !ok {
+		return nil, errors.New("the default event template is missing")
+	}
+	return templates, nil
+}
+
+// defaultTemplateSource returns the default template of the language.
+func defaultTemplateSource(lang string) string {
+	content, err := templateFiles.ReadFile(fmt.Sprintf("event.%s.gohtml", lang))
+	if err != nil {
+		content, _ = templateFiles.ReadFile("event.gohtml")
+	}
+	return string(content)
+}
+
+func templateFuncs(lang string) templating.FuncMap {
+	funcs := sprig.FuncMap()
+	funcs["plural"] = func(n int, forms ...string) string {
+		return i18n.Plural(lang, n, forms...)
+	}
+	return funcs
+}
+
+// customTemplateFuncs are the functions available to custom templates: hermetic sprig functions
+// without the expensive ones.
+func customTemplateFuncs(lang string) templating.FuncMap {
+	funcs := sprig.HermeticHtmlFuncMap()
+	for _, name := range deniedTemplateFuncs {
+		delete(funcs, name)
+	}
+	funcs["plural"] = func(n int, forms ...string) string {
+		return i18n.Plural(lang, n, forms...)
+	}
+	return funcs
+}
+
+// parseCustomTemplate parses a custom template, the source is either the body of the event template
+// or defines the event template. Templates which could keep rendering indefinitely are rejected.
+func parseCustomTemplate(source string, lang string) (*templating.Template, error) {
+	if len(source) > maxTemplateSize {
+		return nil, fmt.Errorf("the template is larger than %d bytes", maxTemplateSize)
+	}
+	t, err := templating.New("event").Funcs(customTemplateFuncs(lang)).Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	for _, defined := range t.Templates() {
+		if defined.Tree == nil {
+			continue
+		}
+		if err = checkTemplateNode(defined.Tree.Root, 0); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// checkTemplateNode rejects calls of templates, which may recurse, ranges over anything but the fields of the event
+// and ranges nested deeper than maxRangeDepth.
+func checkTemplateNode(node parse.Node, rangeDepth int) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplateNode(child, rangeDepth); err != nil {
+				return err
+			}
+		}
+	case *parse.TemplateNode:
+		return fmt.Errorf("templates can't call other templates: %s", n)
+	case *parse.RangeNode:
+		if rangeDepth == maxRangeDepth {
+			return fmt.Errorf("ranges can't be nested more than %d times", maxRangeDepth)
+		}
+		if !isEventField(n.Pipe) {
+			return fmt.Errorf("ranges can only iterate over the fields of the event: %s", n.Pipe)
+		}
+		return checkBranch(&n.BranchNode, rangeDepth+1)
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode, rangeDepth)
+	case *parse.WithNode:
+		return checkBranch(&n.BranchNode, rangeDepth)
+	}
+	return nil
+}
+
+func checkBranch(branch *parse.BranchNode, rangeDepth int) error {
+	if err := checkTemplateNode(branch.List, rangeDepth); err != nil {
+		return err
+	}
+	return checkTemplateNode(branch.ElseList, rangeDepth)
+}
+
+// isEventField checks that the pipeline is a field like .Participants or $.Waitlist without function calls.
+func isEventField(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		return len(arg.Ident) > 1
+	}
+	return false
+}
+
+func renderCustom(source string, l i18n.Localizer, event Event) (string, error) {
+	t, err := parseCustomTemplate(source, l.Lang())
+	if err != nil {
+		return "", err
+	}
+	doc := &limitedBuffer{limit: maxRenderedLength * utf8.UTFMax}
+	if err = t.ExecuteTemplate(doc, "event", event); err != nil {
+		return "", err
+	}
+	text := doc.String()
+	if utf8.RuneCountInString(text) > maxRenderedLength {
+		return "", errRenderedTooLong
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("the rendered event is empty")
+	}
+	return text, nil
+}
+
+// validateTemplate checks that the template parses and renders a sample event in every language.
+func validateTemplate(source string) error {
+	for _, lang := range i18n.Languages() {
+		l := i18n.For(lang)
+		if _, err := renderCustom(source, l, sampleEventView(l)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sampleEventView is an event using all the features of the view, custom templates are tried on it before saving.
+func sampleEventView(l i18n.Localizer) Event {
+	id := int64(1)
+	alice := &model.Participant{Name: "Alice", TelegramId: &id, PaymentStatus: model.PaymentStatus{Paid: true}}
+	event := &model.Event{
+		ChatId:   -1,
+		Creator:  alice,
+		Title:    "Football",
+		Created:  time.Date(2024, time.May, 1, 18, 0, 0, 0, time.UTC),
+		StartsAt: time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC),
+		Status:   model.StatusOpen,
+		Capacity: 3,
+		Price:    750,
+		Currency: "EUR",
+	}
+	event.AddParticipant(alice)
+	event.AddParticipant(&model.Participant{Name: "Bob", InvitedBy: alice})
+	event.AddParticipant(&model.Participant{Name: "Charlie"})
+	event.AddParticipant(&model.Participant{Name: "Dave"})
+	return NewEventView(event, model.DefaultChatSettings(event.ChatId), l)
+}
+
+// limitedBuffer fails writes beyond the limit, so a template can't produce arbitrary large output.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, errRenderedTooLong
+	}
+	return b.Buffer.Write(p)
+}
+
+// eventTemplate manages the custom event template of the chat: /template show, /template set <template>
+// or a reply to a message or a file with the template, and /template reset.
+func (b *TgBot) eventTemplate(ctx context.Context, update tgbotapi.Update, l i18n.Localizer, msg *tgbotapi.MessageConfig) string {
+	chatId := update.Message.Chat.ID
+	// the template usually starts on a new line after the subcommand
+	subcommand, source := cutArgument(update.Message.CommandArguments())
+	switch strings.ToLower(subcommand) {
+	case "show":
+		custom, err := b.eventService.GetEventTemplate(ctx, chatId)
+		if err != nil {
+			msg.Text = l.T("template.get.failed")
+			return metrics.OutcomeError
+		}
+		header, source := l.T("template.default"), defaultTemplateSource(l.Lang())
+		if custom != nil {
+			header, source = l.T("template.custom"), custom.Source
+		}
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.Text = fmt.Sprintf("%s\n<pre>%s</pre>", html.EscapeString(header), html.EscapeString(source))
+		return metrics.OutcomeSuccess
+	case "set", "reset":
+	default:
+		msg.Text = l.T("template.usage")
+		return metrics.OutcomeError
+	}
+
+	hasPermission, err := b.hasPermissionToManageSettings(ctx, update.Message.From, chatId)
+	if err != nil {
+		msg.Text = l.T("permissions.failed")
+		return metrics.OutcomeError
+	}
+	if !hasPermission {
+		msg.Text = l.T("template.denied")
+		return metrics.OutcomePermissionDenied
+	}
+	if strings.EqualFold(subcommand, "reset") {
+		err := b.eventService.ResetEventTemplate(ctx, chatId, newChatUser(chatId, update.Message.From))
+		if isDuplicate(update, err) {
+			return metrics.OutcomeSuccess
+		}
+		if err != nil {
+			msg.Text = l.T("template.save.failed")
+			return metrics.OutcomeError
+		}
+		msg.Text = l.T("template.reset")
+		return metrics.OutcomeSuccess
+	}
+
+	source = strings.TrimSpace(source)
+	if source == "" {
+		source, err = b.repliedTemplate(ctx, update.Message.ReplyToMessage)
+		if err != nil {
+			log.Warn().Msgf("Failed to get the template of the chat %d from the replied message: %s.", chatId, err)
+			msg.Text = l.T("template.invalid", err)
+			return metrics.OutcomeError
+		}
+	}
+	if source == "" {
+		msg.Text = l.T("template.usage")
+		return metrics.OutcomeError
+	}
+	if err := validateTemplate(source); err != nil {
+		msg.Text = l.T("template.invalid", err)
+		return metrics.OutcomeError
+	}
+	err = b.eventService.SaveEventTemplate(ctx, chatId, source, newChatUser(chatId, update.Message.From))
+	if isDuplicate(update, err) {
+		return metrics.OutcomeSuccess
+	}
+	if err != nil {
+		msg.Text = l.T("template.save.failed")
+		return metrics.OutcomeError
+	}
+	msg.Text = l.T("template.saved")
+	return metrics.OutcomeSuccess
+}
+
+// repliedTemplate reads the template from the replied message, either its text or the attached file.
+func (b *TgBot) repliedTemplate(ctx context.Context, reply *tgbotapi.Message) (string, error) {
+	if reply == nil {
+		return "", nil
+	}
+	if reply.Document == nil {
+		return reply.Text, nil
+	}
+	if reply.Document.FileSize > maxTemplateSize {
+		return "", fmt.Errorf("the template is larger than %d bytes", maxTemplateSize)
+	}
+	url, err := b.api(ctx).GetFileDirectURL(reply.Document.FileID)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	// the instrumented client would label the metrics with the file path
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errors.New("failed to download the file")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download the file: %s", resp.Status)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxTemplateSize+1))
+	if err != nil {
+		return "", errors.New("failed to download the file")
+	}
+	if !utf8.Valid(content) {
+		return "", errors.New("the file is not a text file")
+	}
+	return string(content), nil
+}
@@ -0,0 +1,45 @@
+package tgbot
+
+import (
+	"event-gorganizer/internal/i18n"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestValidateTemplate_AcceptsDefaultTemplates(t *testing.T) {
+	for _, lang := range i18n.Languages() {
+		assert.NoErrorf(t, validateTemplate(defaultTemplateSource(lang)), "Default %s template rejected", lang)
+	}
+	assert.NoError(t, validateTemplate(`<b>{{.Title | upper}}</b> {{len .Participants}}/{{.Capacity}}`))
+}
+
+func TestValidateTemplate_RejectsInvalidTemplates(t *testing.T) {
+	for name, source := range map[string]string{
+		"syntax":       `{{.Title`,
+		"unknown":      `{{.Location}}`,
+		"env":          `{{env "TG_KEY"}}`,
+		"repeat":       `{{repeat 100000000 "x"}}`,
+		"empty":        `{{if false}}x{{end}}`,
+		"long output":  `{{range .Participants}}{{$.Title}}{{printf "%5000s" ""}}{{end}}`,
+		"large source": strings.Repeat("x", maxTemplateSize+1),
+		"recursion":    `{{define "event"}}{{.Title}}{{template "event" .}}{{end}}`,
+		"block":        `{{block "list" .}}{{.Title}}{{end}}`,
+		"range of int": `{{.Title}}{{range 1000000000}}{{end}}`,
+		"range of seq": `{{.Title}}{{range $n := list 1 2 3}}{{end}}`,
+		"deep ranges":  `{{range .Participants}}{{range $.Participants}}{{range $.Participants}}{{end}}{{end}}{{end}}`,
+	} {
+		assert.Errorf(t, validateTemplate(source), "Template with %s accepted", name)
+	}
+}
+
+func TestValidateTemplate_AcceptsDefineAndNestedRanges(t *testing.T) {
+	assert.NoError(t, validateTemplate(`{{define "event"}}{{.Title}}{{range $p := .Participants}}{{range $.Waitlist}}{{$p.Name}}{{end}}{{end}}{{end}}`))
+}
+
+func TestRenderCustom_Plural(t *testing.T) {
+	l := i18n.For("ru")
+	text, err := renderCustom(`{{$n := len .Participants}}{{$n}} {{plural $n "участник" "участника" "участников"}}`, l, sampleEventView(l))
+	assert.NoError(t, err)
+	assert.Equal(t, "3 участника", text)
+}
@@ -0,0 +1,36 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// undo handles /undo, it reverts the latest change of the sender to the event. Admins, owners and organizers
+// revert the latest change of anyone.
+func (b *TgBot) undo(ctx context.Context, update tgbotapi.Update, l i18n.Localizer) (string, string) {
+	chatId := update.Message.Chat.ID
+	change, err := b.eventService.Undo(ctx, chatId, newChatUser(chatId, update.Message.From))
+	if text, outcome, ok := rejection(l, err); ok {
+		return text, outcome
+	}
+	switch {
+	case isDuplicate(update, err):
+		return "", metrics.OutcomeSuccess
+	case errors.Is(err, service.ErrNothingToUndo):
+		return l.T("undo.nothing"), metrics.OutcomeError
+	case errors.Is(err, service.ErrUndoExpired):
+		return l.N("undo.expired", int(b.eventService.UndoWindow().Minutes())), metrics.OutcomeError
+	case errors.Is(err, service.ErrUndoConflict):
+		return l.T("undo.conflict"), metrics.OutcomeError
+	case err != nil:
+		log.Error().Msgf("Failed to undo a change in the chat %d: %s.", chatId, err)
+		return l.T("undo.failed"), metrics.OutcomeError
+	}
+	description := l.T("log.action."+change.Action, change.ActorName, change.Target, change.Before, change.After)
+	return l.T("undo.done", description), metrics.OutcomeSuccess
+}
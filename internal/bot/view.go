@@ -1,6 +1,7 @@
 package tgbot
 
 import (
+	"event-gorganizer/internal/i18n"
 	"event-gorganizer/internal/model"
 	"fmt"
 	"time"
@@ -12,8 +13,25 @@ type Event struct {
 	Creator      Participant
 	Title        string
 	Participants []Participant
-	Created      time.Time
-	Active       bool
+	Waitlist     []Participant
+	// Guests is the number of guests among the participants and the waitlist.
+	Guests int
+	// Capacity is 0 for events without a limit.
+	Capacity int
+	// Price is formatted with the currency, empty for free events.
+	Price   string
+	Created time.Time
+	Active  bool
+	// Status is draft, open, closed, cancelled or completed, CancelReason may be set for cancelled events.
+	Status       string
+	CancelReason string
+	// StartsAt is the kickoff, zero if it wasn't set.
+	StartsAt time.Time
+	// RegistrationOpensAt is zero unless registration opens later, RegistrationClosesAt is zero unless it's open
+	// and closes later.
+	RegistrationOpensAt  time.Time
+	RegistrationClosesAt time.Time
+	RegistrationOpen     bool
 }
 
 type Participant struct {
@@ -21,17 +39,55 @@ type Participant struct {
 	Name          string
 	Title         string
 	PaymentStatus PaymentStatus
+	// Guests is the number of guests the participant invited.
+	Guests int
 }
 
 type PaymentStatus struct {
 	Paid bool
 }
 
-func NewEventView(e *model.Event) Event {
+// NewEventView prepares the event for rendering, times are in the timezone of the chat.
+func NewEventView(e *model.Event, settings *model.ChatSettings, l i18n.Localizer) Event {
 
-	var participants []Participant
+	attending, waitlisted := e.Lineup()
+	guests := 0
 	for _, p := range e.Participants {
-		participants = append(participants, NewParticipantView(p))
+		if p.InvitedBy != nil {
+			guests++
+		}
+	}
+	view := func(p *model.Participant) Participant {
+		participant := NewParticipantView(p, l)
+		if invited := len(e.GuestsOf(p.Id())); invited > 0 {
+			participant.Guests = invited
+			participant.Title += " " + l.N("participant.guests", invited)
+		}
+		return participant
+	}
+	var participants []Participant
+	for _, p := range attending {
+		participants = append(participants, view(p))
+	}
+	var waitlist []Participant
+	for _, p := range waitlisted {
+		waitlist = append(waitlist, view(p))
+	}
+	var price string
+	if e.Price > 0 {
+		price = model.FormatPrice(e.Price, e.Currency)
+	}
+	loc := settings.Location()
+	now := time.Now()
+	var opensAt, closesAt time.Time
+	if e.RegistrationUpcoming(now) {
+		opensAt = e.RegistrationOpensAt.In(loc)
+	} else if e.RegistrationOpen(now) && !e.RegistrationClosesAt.IsZero() {
+		closesAt = e.RegistrationClosesAt.In(loc)
+	}
+	var startsAt time.Time
+	if !e.StartsAt.IsZero() {
+		startsAt = e.StartsAt.In(loc)
 	}
 
 	return Event{
@@ -40,30 +96,40 @@ func NewEventView(e *model.Event) Event {
 		Creator: Participant{
 			Number:        e.Creator.Number,
 			Name:          e.Creator.Name,
-			Title:         getTitle(*e.Creator),
+			Title:         getTitle(*e.Creator, l),
 			PaymentStatus: PaymentStatus{Paid: e.Creator.PaymentStatus.Paid},
 		},
-		Title:        e.Title,
-		Participants: participants,
-		Created:      e.Created,
-		Active:       e.Active,
+		Title:                e.Title,
+		Participants:         participants,
+		Waitlist:             waitlist,
+		Guests:               guests,
+		Capacity:             e.Capacity,
+		Price:                price,
+		Created:              e.Created.In(loc),
+		Active:               e.IsActive(),
+		Status:               string(e.Status),
+		CancelReason:         e.CancelReason,
+		StartsAt:             startsAt,
+		RegistrationOpensAt:  opensAt,
+		RegistrationClosesAt: closesAt,
+		RegistrationOpen:     e.RegistrationOpen(now),
 	}
 }
 
-func NewParticipantView(p *model.Participant) Participant {
+func NewParticipantView(p *model.Participant, l i18n.Localizer) Participant {
 	return Participant{
 		Number:        p.Number,
 		Name:          p.Name,
-		Title:         getTitle(*p),
+		Title:         getTitle(*p, l),
 		PaymentStatus: PaymentStatus{Paid: p.PaymentStatus.Paid},
 	}
 }
 
-func getTitle(p model.Participant) string {
+func getTitle(p model.Participant, l i18n.Localizer) string {
 	var title string
 
 	if p.InvitedBy != nil {
-		title = fmt.Sprintf("#%d: %s (invited by @%s)", p.Number, p.Name, p.InvitedBy.Name)
+		title = fmt.Sprintf("#%d: %s %s", p.Number, p.Name, l.T("participant.invited.by", p.InvitedBy.Name))
 	} else {
 		title = fmt.Sprintf("#%d: %s", p.Number, p.Name)
 	}
@@ -0,0 +1,94 @@
+package tgbot
+
+import (
+	"event-gorganizer/internal/i18n"
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRenderEvent_Localized(t *testing.T) {
+	templates, err := getTemplates()
+	assert.NoError(t, err)
+	b := &TgBot{eventTemplates: templates}
+	event := &model.Event{ChatId: 1, Title: "Football", Created: time.Now(), Creator: &model.Participant{Name: "Alice"}, Capacity: 2}
+	alice := &model.Participant{Name: "Alice"}
+	event.AddParticipant(alice)
+	event.AddParticipant(&model.Participant{Name: "Bob", InvitedBy: alice})
+	event.AddParticipant(&model.Participant{Name: "Charlie"})
+	settings := model.DefaultChatSettings(1)
+
+	for lang, expected := range map[string]string{
+		"en": "Participants: 2/2",
+		"ru": "2 участника из 2",
+		"de": "2 von 2 Teilnehmern",
+		"fr": "Participants: 2/2",
+	} {
+		l := i18n.For(lang)
+		text := b.renderDefault(l, NewEventView(event, settings, l))
+		assert.Containsf(t, text, expected, "Unexpected %s rendering", lang)
+		assert.Containsf(t, text, "#3: Charlie", "Waitlist isn't rendered in %s", lang)
+		assert.Containsf(t, text, l.T("participant.invited.by", "Alice"), "Inviter isn't rendered in %s", lang)
+	}
+}
+
+func TestRenderEvent_Registration(t *testing.T) {
+	templates, err := getTemplates()
+	assert.NoError(t, err)
+	b := &TgBot{eventTemplates: templates}
+	settings := model.DefaultChatSettings(1)
+	assert.NoError(t, settings.Set(model.SettingTimezone, "Europe/Berlin"))
+	kickoff := time.Date(2030, time.May, 25, 16, 0, 0, 0, time.UTC)
+	event := &model.Event{ChatId: 1, Title: "Football", Created: time.Now(), Creator: &model.Participant{Name: "Alice"}}
+	event.ScheduleKickoff(kickoff, 6, time.Now())
+	l := i18n.For("en")
+
+	text := b.renderDefault(l, NewEventView(event, settings, l))
+	assert.Contains(t, text, "Kickoff: 25.05 18:00")
+	assert.Contains(t, text, "Registration closes: 25.05 12:00")
+
+	event.RegistrationClosesAt = time.Time{}
+	event.RegistrationOpensAt = kickoff.Add(-48 * time.Hour)
+	text = b.renderDefault(l, NewEventView(event, settings, l))
+	assert.Contains(t, text, "Registration opens: 23.05 18:00")
+
+	event.RegistrationOpensAt = time.Time{}
+	event.RegistrationClosesAt = time.Now().Add(-time.Minute)
+	text = b.renderDefault(l, NewEventView(event, settings, l))
+	assert.Contains(t, text, "Registration is closed")
+}
+
+func TestRegistrationText(t *testing.T) {
+	l := i18n.For("en")
+	now := time.Date(2024, time.May, 20, 12, 0, 0, 0, time.UTC)
+	event := &model.Event{}
+	assert.Equal(t, l.T("registration.open"), registrationText(l, event, now, time.UTC))
+
+	event.ScheduleKickoff(now.Add(24*time.Hour), 2, now)
+	assert.Equal(t, "Kickoff: 21.05.2024 12:00.\nRegistration closes at 21.05.2024 10:00.", registrationText(l, event, now, time.UTC))
+
+	event.RegistrationClosesAt = now
+	assert.Equal(t, "Kickoff: 21.05.2024 12:00.\nRegistration is closed.", registrationText(l, event, now, time.UTC))
+}
+
+func TestNewEventView_GuestCounts(t *testing.T) {
+	id := int64(1)
+	alice := &model.Participant{Name: "Alice", TelegramId: &id}
+	event := &model.Event{ChatId: 1, Title: "Football", Created: time.Now(), Creator: alice}
+	event.AddParticipant(alice)
+	event.AddParticipant(&model.Participant{Name: "Bob", InvitedBy: alice})
+	event.AddParticipant(&model.Participant{Name: "Charlie", InvitedBy: alice})
+	l := i18n.For("en")
+
+	view := NewEventView(event, model.DefaultChatSettings(1), l)
+	assert.Equal(t, 2, view.Guests)
+	assert.Equal(t, 2, view.Participants[0].Guests)
+	assert.Equal(t, "#1: Alice +2 guests", view.Participants[0].Title)
+	assert.Zero(t, view.Participants[1].Guests)
+
+	templates, err := getTemplates()
+	assert.NoError(t, err)
+	b := &TgBot{eventTemplates: templates}
+	assert.Contains(t, b.renderDefault(l, view), "Guests: 2")
+}
@@ -0,0 +1,138 @@
+package tgbot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	secretTokenHeader     = "X-Telegram-Bot-Api-Secret-Token"
+	defaultMaxConnections = 40
+	webhookCheckInterval  = 10 * time.Minute
+)
+
+// allowedUpdates are the update types the bot subscribes to.
+var allowedUpdates = []string{"message", "callback_query", "chat_member", "my_chat_member"}
+
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+type WebhookSettings struct {
+	// Url is the public base url of the service, the webhook is registered on startup.
+	Url string
+	// PathSecret makes the webhook path hard to guess.
+	PathSecret string
+	// SecretToken is sent by Telegram in the X-Telegram-Bot-Api-Secret-Token header of every request.
+	SecretToken    string
+	MaxConnections int
+}
+
+func (s WebhookSettings) path() string {
+	return "/" + s.PathSecret
+}
+
+func (s WebhookSettings) url() string {
+	return strings.TrimSuffix(s.Url, "/") + s.path()
+}
+
+func (s WebhookSettings) validate() error {
+	if s.Url == "" {
+		return fmt.Errorf("webhook url is empty")
+	}
+	if s.PathSecret == "" {
+		return fmt.Errorf("webhook path secret is empty")
+	}
+	if !secretTokenPattern.MatchString(s.SecretToken) {
+		return fmt.Errorf("webhook secret token must be 1-256 characters of A-Z, a-z, 0-9, _ and -")
+	}
+	return nil
+}
+
+// registerWebhook points Telegram to the webhook url with the secret token. Registering again clears
+// the delivery errors, Telegram keeps the pending updates and delivers them to the new registration.
+func (b *TgBot) registerWebhook(ctx context.Context) error {
+	maxConnections := b.webhook.MaxConnections
+	if maxConnections <= 0 {
+		maxConnections = defaultMaxConnections
+	}
+	params := tgbotapi.Params{
+		"url":          b.webhook.url(),
+		"secret_token": b.webhook.SecretToken,
+	}
+	params.AddNonZero("max_connections", maxConnections)
+	if err := params.AddInterface("allowed_updates", allowedUpdates); err != nil {
+		return err
+	}
+	if _, err := b.api(ctx).MakeRequest("setWebhook", params); err != nil {
+		log.Error().Msgf("Failed to register the webhook: %s.", err)
+		return err
+	}
+	log.Info().Msgf("Webhook registered, allowed updates: %v, max connections: %d.", allowedUpdates, maxConnections)
+	return nil
+}
+
+// checkWebhook registers the webhook again if it points elsewhere, past delivery errors are only logged
+// as Telegram keeps retrying the updates.
+func (b *TgBot) checkWebhook(ctx context.Context) error {
+	info, err := b.api(ctx).GetWebhookInfo()
+	if err != nil {
+		log.Error().Msgf("Failed to get the webhook info: %s.", err)
+		return err
+	}
+	if info.LastErrorDate != 0 {
+		log.Warn().Msgf("Telegram failed to deliver updates at %s: %s, pending updates: %d.",
+			time.Unix(int64(info.LastErrorDate), 0).Format(time.RFC3339), info.LastErrorMessage, info.PendingUpdateCount)
+	}
+	if info.URL != b.webhook.url() {
+		log.Warn().Msg("The webhook is not registered, registering it again.")
+		return b.registerWebhook(ctx)
+	}
+	return nil
+}
+
+// watchWebhook checks the webhook registration periodically until the context is cancelled.
+func (b *TgBot) watchWebhook(ctx context.Context) {
+	ticker := time.NewTicker(webhookCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = b.checkWebhook(ctx)
+		}
+	}
+}
+
+// webhookHandler accepts updates pushed by Telegram. Requests without the secret token are rejected.
+// The response is sent once the update is queued, so a full queue makes Telegram wait
+// and a stopped bot makes it retry later.
+func (b *TgBot) webhookHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(secretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(b.webhook.SecretToken)) != 1 {
+			log.Warn().Msgf("Rejected a webhook request from %s without a valid secret token.", r.RemoteAddr)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		update, err := b.bot.HandleUpdate(r)
+		if err != nil {
+			errMsg, _ := json.Marshal(map[string]string{"error": err.Error()})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write(errMsg)
+			return
+		}
+		if !b.dispatcher.dispatch(*update) {
+			log.Warn().Msgf("The bot is stopping, the update %d is rejected.", update.UpdateID)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package tgbot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookHandler_RejectsRequestWithoutSecretToken(t *testing.T) {
+	b := newWebhookTestBot()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/path-secret", strings.NewReader(`{"update_id": 1}`))
+	b.webhookHandler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, int64(0), b.dispatcher.stats().Enqueued)
+}
+
+func TestWebhookHandler_RejectsRequestWithWrongSecretToken(t *testing.T) {
+	b := newWebhookTestBot()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/path-secret", strings.NewReader(`{"update_id": 1}`))
+	req.Header.Set(secretTokenHeader, "wrong")
+	b.webhookHandler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestWebhookHandler_AcceptsRequestWithSecretToken(t *testing.T) {
+	b := newWebhookTestBot()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/path-secret", strings.NewReader(`{"update_id": 1}`))
+	req.Header.Set(secretTokenHeader, "secret-token")
+	b.webhookHandler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(1), b.dispatcher.stats().Enqueued)
+}
+
+func TestWebhookSettings_Validate(t *testing.T) {
+	settings := WebhookSettings{Url: "https://bot.example.com/", PathSecret: "path-secret", SecretToken: "secret-token"}
+	assert.NoError(t, settings.validate())
+	assert.Equal(t, "https://bot.example.com/path-secret", settings.url())
+
+	settings.SecretToken = "not allowed!"
+	assert.Error(t, settings.validate())
+}
+
+func newWebhookTestBot() *TgBot {
+	return &TgBot{
+		bot:        &tgbotapi.BotAPI{},
+		webhook:    &WebhookSettings{Url: "https://bot.example.com", PathSecret: "path-secret", SecretToken: "secret-token"},
+		dispatcher: newDispatcher(1, 1, func(ctx context.Context, update tgbotapi.Update, chatId int64) {}),
+	}
+}
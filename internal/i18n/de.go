@@ -0,0 +1,201 @@
+package i18n
+
+var de = map[string]string{
+	"command.unknown":    "Unbekannter Befehl: %s.",
+	"permissions.failed": "Berechtigungen konnten nicht geprüft werden.",
+
+	"event.created":          "Veranstaltung erstellt.",
+	"event.create.failed":    "Veranstaltung konnte nicht erstellt werden.",
+	"event.create.denied":    "Veranstaltung wurde nicht erstellt, keine Berechtigung.",
+	"event.get.failed":       "Aktuelle Veranstaltung konnte nicht geladen werden.",
+	"event.full":             "Die Veranstaltung ist voll.",
+	"event.none":             "Es gibt noch keine Veranstaltung, erstelle eine mit /new.",
+	"event.closed":           "Die letzte Veranstaltung ist geschlossen, erstelle eine neue mit /new.",
+	"event.cancelled":        "Die letzte Veranstaltung %s wurde abgesagt, erstelle eine neue mit /new.",
+	"event.cancelled.reason": "Die letzte Veranstaltung %s wurde abgesagt: %s. Erstelle eine neue mit /new.",
+	"guests.not.allowed":     "In diesem Chat sind keine Gäste erlaubt.",
+	"guests.limit":           "Du kannst zu dieser Veranstaltung keine weiteren Gäste einladen.",
+	"guests.prompt.one":      "Du hast %[2]s eingeladen, den Gast auch entfernen?",
+	"guests.prompt.other":    "Du hast %[2]s eingeladen, die %[1]d Gäste auch entfernen?",
+	"guests.remove.button":   "Gäste entfernen",
+	"guests.keep.button":     "Behalten",
+	"guests.removed":         "Entfernt: %s.",
+	"guests.kept":            "Die Gäste bleiben auf der Liste.",
+	"guests.none":            "Es gibt keine Gäste zum Entfernen.",
+	"guests.prompt.denied":   "Nur der Einladende kann antworten.",
+	"guests.remove.failed":   "Die Gäste konnten nicht entfernt werden.",
+
+	"participant.added":              "%s ist dabei.",
+	"participant.added.by":           "%s wurde von %s hinzugefügt.",
+	"participant.add.failed":         "%s konnte nicht hinzugefügt werden.",
+	"participant.removed":            "%s kommt nicht.",
+	"participant.add.denied":         "Keine Berechtigung, %s hinzuzufügen, füge die Person als Gast mit /i Name hinzu.",
+	"participant.unknown":            "%s hat noch nicht in den Chat geschrieben, füge die Person als Gast mit /i Name hinzu.",
+	"participant.remove.failed":      "%v konnte nicht entfernt werden.",
+	"participant.remove.denied":      "Keine Berechtigung, %s zu entfernen.",
+	"participant.edit.failed":        "%v konnte nicht geändert werden.",
+	"participant.edit.denied":        "Keine Berechtigung, %s zu ändern.",
+	"participant.rename.usage":       "Verwendung: /rename N Neuer Name.",
+	"participant.renamed":            "#%d heißt jetzt %s.",
+	"participant.name.taken":         "Ein anderer Gast heißt bereits %s.",
+	"participant.move.usage":         "Verwendung: /move N M setzt #N an die Stelle von #M.",
+	"participant.move.denied":        "Nur Admins und Organisatoren können Teilnehmer nach vorne verschieben.",
+	"participant.moved":              "%s ist jetzt #%d.",
+	"participant.move.missing":       "#%d oder #%d ist nicht in der Liste.",
+	"participant.inviter.usage":      "Verwendung: /inviter N @user, oder auf eine Nachricht des Nutzers mit /inviter N antworten.",
+	"participant.inviter.changed":    "%s ist jetzt Gast von %s.",
+	"participant.not.guest":          "#%d ist Chatmitglied, nur Gäste haben Einladende.",
+	"participant.number.invalid":     "Ungültige Teilnehmernummer: %s.",
+	"participant.not.found":          "Teilnehmer mit der Nummer %d nicht gefunden.",
+	"participant.guests.one":         "+%d Gast",
+	"participant.guests.other":       "+%d Gäste",
+	"participant.not.registered":     "%s steht nicht auf der Liste.",
+	"participant.already.registered": "%s steht bereits auf der Liste.",
+	"participant.invited.by":         "(eingeladen von @%s)",
+
+	"paid.marked":     "%s hat bezahlt.",
+	"paid.failed":     "Zahlung konnte nicht vermerkt werden.",
+	"paid.failed.for": "Zahlung von %v konnte nicht vermerkt werden.",
+	"paid.denied":     "Keine Berechtigung, Zahlungen zu vermerken.",
+
+	"role.granted":       "%s ist jetzt %s.",
+	"role.grant.failed":  "Rolle konnte nicht vergeben werden.",
+	"role.grant.denied":  "Keine Berechtigung, Rollen zu vergeben.",
+	"role.grant.usage":   "Verwendung: /grant @user owner|organizer|treasurer|member, oder auf eine Nachricht des Nutzers mit /grant Rolle antworten.",
+	"role.revoked":       "%s ist nicht mehr %s.",
+	"role.none":          "%s hat keine Rolle.",
+	"role.revoke.failed": "Rolle konnte nicht entzogen werden.",
+	"role.revoke.denied": "Keine Berechtigung, Rollen zu entziehen.",
+	"role.revoke.usage":  "Verwendung: /revoke @user, oder auf eine Nachricht des Nutzers mit /revoke antworten.",
+	"roles.list.one":     "%d Rolle:",
+	"roles.list.other":   "%d Rollen:",
+	"roles.empty":        "Keine Rollen vergeben.",
+	"roles.get.failed":   "Rollen konnten nicht geladen werden.",
+
+	"settings.title":           "Einstellungen:",
+	"settings.help":            "Einstellung ändern mit /settings Name Wert.",
+	"settings.changed":         "%s ist jetzt %s.",
+	"settings.get.failed":      "Einstellungen konnten nicht geladen werden.",
+	"settings.change.failed":   "Einstellung konnte nicht geändert werden.",
+	"settings.change.denied":   "Keine Berechtigung, Einstellungen zu ändern.",
+	"settings.invalid":         "%s wurde nicht geändert, %s",
+	"settings.unknown":         "Unbekannte Einstellung %s, verfügbar sind %s.",
+	"settings.hint.timezone":   "gib eine Zeitzone wie Europe/Berlin an.",
+	"settings.hint.language":   "gib en, ru oder de an.",
+	"settings.hint.capacity":   "gib die Teilnehmerzahl an, 0 bedeutet unbegrenzt.",
+	"settings.hint.price":      "gib einen Betrag wie 10 oder 7.50 an.",
+	"settings.hint.currency":   "gib einen Währungscode wie EUR an.",
+	"settings.hint.guests":     "gib on oder off an.",
+	"settings.hint.waitlist":   "gib on oder off an.",
+	"settings.hint.remove":     "gib anyone, inviter oder admins an.",
+	"settings.hint.reminders":  "gib Stunden vor der Veranstaltung wie 24,2 oder off an.",
+	"settings.hint.closing":    "gib Stunden vor dem Anpfiff wie 6 oder off an.",
+	"settings.hint.early":      "gib Stunden vor dem Öffnen der Anmeldung wie 24 oder off an.",
+	"settings.hint.bump":       "gib on oder off an.",
+	"settings.hint.guestlimit": "gib eine Anzahl Gäste pro Teilnehmer an, 0 bedeutet unbegrenzt.",
+
+	"template.usage":       "Verwendung: /template show, /template set mit der Vorlage oder als Antwort auf eine Nachricht oder Datei mit ihr, /template reset.",
+	"template.default":     "Standardvorlage:",
+	"template.custom":      "Vorlage des Chats:",
+	"template.get.failed":  "Vorlage konnte nicht geladen werden.",
+	"template.denied":      "Keine Berechtigung, die Vorlage zu ändern.",
+	"template.invalid":     "Vorlage wurde nicht gespeichert: %s.",
+	"template.save.failed": "Vorlage konnte nicht gespeichert werden.",
+	"template.saved":       "Vorlage gespeichert.",
+	"template.reset":       "Jetzt wird die Standardvorlage verwendet.",
+
+	"log.title":                 "Letzte Änderungen:",
+	"log.empty":                 "Noch keine Änderungen.",
+	"log.usage":                 "Verwende /log oder /log <Anzahl der Änderungen>.",
+	"log.denied":                "Nur Admins können das Protokoll sehen.",
+	"log.failed":                "Protokoll konnte nicht geladen werden.",
+	"log.action.create":         "%[1]s hat %[2]s erstellt",
+	"log.action.close":          "%[1]s hat %[2]s geschlossen",
+	"log.action.add":            "%[1]s hat %[2]s hinzugefügt",
+	"log.action.remove":         "%[1]s hat %[2]s entfernt",
+	"log.action.remove_guests":  "%[1]s hat die Gäste von %[2]s entfernt: %[3]s",
+	"log.action.paid":           "%[1]s hat %[2]s als bezahlt markiert",
+	"log.action.rename":         "%[1]s hat %[3]s in %[4]s umbenannt",
+	"log.action.move":           "%[1]s hat %[2]s von %[3]s nach %[4]s verschoben",
+	"log.action.set_inviter":    "%[1]s hat den Einladenden von %[2]s von %[3]s zu %[4]s geändert",
+	"log.action.setting":        "%[1]s hat %[2]s von %[3]s zu %[4]s geändert",
+	"log.action.template":       "%[1]s hat eine eigene Vorlage gesetzt",
+	"log.action.reset_template": "%[1]s hat die Vorlage zurückgesetzt",
+	"log.action.grant":          "%[1]s hat %[2]s die Rolle %[4]s gegeben",
+	"log.action.revoke":         "%[1]s hat %[2]s die Rolle %[3]s entzogen",
+	"log.action.undo":           "%[1]s hat die Änderung von %[2]s rückgängig gemacht",
+	"log.action.kickoff":        "%[1]s hat den Anpfiff von %[2]s auf %[4]s gesetzt",
+	"log.action.opens":          "%[1]s hat die Anmeldung für %[2]s auf %[4]s gesetzt",
+	"log.action.close_signup":   "%[1]s hat die Anmeldung für %[2]s geschlossen",
+	"log.action.reopen_signup":  "%[1]s hat die Anmeldung für %[2]s wieder geöffnet",
+	"log.action.cancel":         "%[1]s hat %[2]s abgesagt",
+	"log.action.reschedule":     "%[1]s hat %[2]s auf %[4]s verschoben",
+
+	"undo.done":          "Rückgängig gemacht: %s.",
+	"undo.nothing":       "Es gibt keine Änderung von dir, die rückgängig gemacht werden kann.",
+	"undo.expired.one":   "Änderungen können innerhalb von %d Minute rückgängig gemacht werden.",
+	"undo.expired.other": "Änderungen können innerhalb von %d Minuten rückgängig gemacht werden.",
+	"undo.conflict":      "Die Veranstaltung wurde seitdem von jemand anderem geändert, bitte einen Admin, es rückgängig zu machen.",
+	"undo.failed":        "Änderung konnte nicht rückgängig gemacht werden.",
+
+	"registration.usage":        "Gib eine Zeit in der Zeitzone des Chats an, z. B. /%[1]s 25.05 18:00, /%[1]s Sa 18:00 oder /%[1]s 25.05.2024 18:00.",
+	"registration.kickoff":      "Anpfiff: %s.",
+	"registration.opens":        "Die Anmeldung öffnet am %s.",
+	"registration.closes":       "Die Anmeldung schließt am %s.",
+	"registration.open":         "Die Anmeldung ist offen.",
+	"registration.closed":       "Die Anmeldung ist geschlossen.",
+	"registration.not.open":     "Die Anmeldung ist noch nicht offen, /event zeigt, wann sie öffnet.",
+	"registration.invalid":      "Die Anmeldung muss öffnen, bevor sie schließt.",
+	"registration.denied":       "Keine Berechtigung, die Anmeldezeiten zu ändern.",
+	"cancel.done":               "%s ist abgesagt, die Teilnehmer wurden benachrichtigt.",
+	"cancel.denied":             "Keine Berechtigung, die Veranstaltung abzusagen.",
+	"cancel.failed":             "Die Veranstaltung konnte nicht abgesagt werden.",
+	"history.title":             "Letzte Veranstaltungen:",
+	"history.empty":             "Noch keine Veranstaltungen.",
+	"history.usage":             "Verwende /history oder /history <Anzahl der Veranstaltungen>.",
+	"history.failed":            "Die Veranstaltungen konnten nicht geladen werden.",
+	"history.event":             "%s %s: %s",
+	"history.status.draft":      "Entwurf",
+	"history.status.open":       "offen",
+	"history.status.closed":     "geschlossen",
+	"history.status.cancelled":  "abgesagt",
+	"history.status.completed":  "abgeschlossen",
+	"registration.failed":       "Die Anmeldezeiten konnten nicht geändert werden.",
+	"registration.announcement": "Die Anmeldung ist offen, melde dich mit /i an!",
+	"reminder.kickoff":          "Erinnerung: %s beginnt am %s. Melde dich mit /i an oder mit /cant ab.",
+
+	"private.open":              "Öffne den Bot im privaten Chat, um deine Veranstaltungen zu sehen und dich dort anzumelden.",
+	"private.open.button":       "Im privaten Chat öffnen",
+	"private.dashboard.title":   "Deine anstehenden Veranstaltungen:",
+	"private.dashboard.empty":   "Du hast keine anstehenden Veranstaltungen. Öffne den Bot mit dem Button unter /event in einer Gruppe, um ihre Veranstaltungen hier zu sehen.",
+	"private.status.none":       "nicht angemeldet",
+	"private.status.attending":  "dabei als #%d",
+	"private.status.waitlisted": "auf der Warteliste als #%d",
+	"private.balance.due":       "Offen für dich und deine Gäste: %s.",
+	"private.balance.settled":   "Nichts offen.",
+	"private.hint":              "/i, /cant, /paid und /event hier ändern die Veranstaltung der ausgewählten Gruppe.",
+	"private.selected":          "%s ausgewählt.",
+	"private.select":            "Wähle zuerst eine Gruppe: öffne den Bot mit dem Button unter /event in der Gruppe.",
+	"private.not.member":        "Du bist kein Mitglied der Gruppe.",
+	"private.failed":            "Deine Veranstaltungen konnten nicht geladen werden.",
+	"private.join.button":       "Anmelden: %s",
+	"private.leave.button":      "Abmelden: %s",
+
+	"notifications.button":           "🔔 Benachrichtigungen",
+	"notifications.title":            "Wähle, worüber der Bot dich im privaten Chat informiert:",
+	"notifications.failed":           "Die Benachrichtigungen konnten nicht geändert werden.",
+	"notifications.topic.waitlist":   "Platz von der Warteliste",
+	"notifications.topic.event":      "Veranstaltung abgesagt oder verschoben",
+	"notifications.topic.payment":    "Zahlungserinnerungen",
+	"notifications.topic.membership": "Von jemand anderem an- oder abgemeldet",
+	"notification.promoted":          "%s hat einen Platz bei %s bekommen.",
+	"notification.added":             "%s hat dich bei %s angemeldet.",
+	"notification.removed":           "%s hat dich von %s abgemeldet.",
+	"notification.rescheduled":       "%s wurde auf %s verschoben.",
+	"notification.payment":           "Bitte zahle %s für %s.",
+	"notification.cancelled":         "%s hat %s abgesagt.",
+	"notification.cancelled.reason":  "%s hat %s abgesagt: %s.",
+	"remind.sent.one":                "%[1]d Teilnehmer wurde an die Zahlung erinnert.",
+	"remind.sent.other":              "%[1]d Teilnehmer wurden an die Zahlung erinnert.",
+	"remind.failed":                  "Die Zahlungserinnerungen konnten nicht gesendet werden.",
+}
@@ -0,0 +1,201 @@
+package i18n
+
+var en = map[string]string{
+	"command.unknown":    "Unknown command: %s.",
+	"permissions.failed": "Failed to check permissions.",
+
+	"event.created":          "Event created.",
+	"event.create.failed":    "Failed to create an event.",
+	"event.create.denied":    "Event wasn't created, not enough rights.",
+	"event.get.failed":       "Failed to get an active event.",
+	"event.full":             "The event is full.",
+	"event.none":             "There is no event yet, create one with /new.",
+	"event.closed":           "The last event is closed, create a new one with /new.",
+	"event.cancelled":        "The last event %s was cancelled, create a new one with /new.",
+	"event.cancelled.reason": "The last event %s was cancelled: %s. Create a new one with /new.",
+	"guests.not.allowed":     "Guests are not allowed in this chat.",
+	"guests.limit":           "You can't invite more guests to this event.",
+	"guests.prompt.one":      "You invited %[2]s, remove the guest too?",
+	"guests.prompt.other":    "You invited %[2]s, remove the %[1]d guests too?",
+	"guests.remove.button":   "Remove guests",
+	"guests.keep.button":     "Keep them",
+	"guests.removed":         "Removed %s.",
+	"guests.kept":            "The guests stay in the list.",
+	"guests.none":            "There are no guests to remove.",
+	"guests.prompt.denied":   "Only the inviter can answer.",
+	"guests.remove.failed":   "Failed to remove the guests.",
+
+	"participant.added":              "%s added.",
+	"participant.added.by":           "%s added by %s.",
+	"participant.add.failed":         "Failed to add %s.",
+	"participant.removed":            "%s won't attend.",
+	"participant.add.denied":         "Not enough rights to add %s, add them as a guest with /i Name.",
+	"participant.unknown":            "%s didn't write to the chat yet, add them as a guest with /i Name.",
+	"participant.remove.failed":      "Failed to remove %v.",
+	"participant.remove.denied":      "Not enough rights to remove %s.",
+	"participant.edit.failed":        "Failed to change %v.",
+	"participant.edit.denied":        "Not enough rights to change %s.",
+	"participant.rename.usage":       "Usage: /rename N New Name.",
+	"participant.renamed":            "#%d is %s now.",
+	"participant.name.taken":         "Another guest is called %s already.",
+	"participant.move.usage":         "Usage: /move N M puts #N to the place of #M.",
+	"participant.move.denied":        "Only admins and organizers can move participants ahead.",
+	"participant.moved":              "%s is #%d now.",
+	"participant.move.missing":       "#%d or #%d is not in the list.",
+	"participant.inviter.usage":      "Usage: /inviter N @user, or reply to a message of the user with /inviter N.",
+	"participant.inviter.changed":    "%s is invited by %s now.",
+	"participant.not.guest":          "#%d is a chat member, only guests have inviters.",
+	"participant.number.invalid":     "Incorrect participant number: %s.",
+	"participant.not.found":          "A participant with number %d not found.",
+	"participant.guests.one":         "+%d guest",
+	"participant.guests.other":       "+%d guests",
+	"participant.not.registered":     "%s is not in the list.",
+	"participant.already.registered": "%s is already in the list.",
+	"participant.invited.by":         "(invited by @%s)",
+
+	"paid.marked":     "%s paid.",
+	"paid.failed":     "Failed to mark as paid.",
+	"paid.failed.for": "Failed to mark paid %v.",
+	"paid.denied":     "Not enough rights to mark as paid.",
+
+	"role.granted":       "%s is %s now.",
+	"role.grant.failed":  "Failed to grant the role.",
+	"role.grant.denied":  "Not enough rights to grant roles.",
+	"role.grant.usage":   "Usage: /grant @user owner|organizer|treasurer|member, or reply to a message of the user with /grant role.",
+	"role.revoked":       "%s is no longer %s.",
+	"role.none":          "%s has no role.",
+	"role.revoke.failed": "Failed to revoke the role.",
+	"role.revoke.denied": "Not enough rights to revoke roles.",
+	"role.revoke.usage":  "Usage: /revoke @user, or reply to a message of the user with /revoke.",
+	"roles.list.one":     "%d role:",
+	"roles.list.other":   "%d roles:",
+	"roles.empty":        "No roles granted.",
+	"roles.get.failed":   "Failed to get roles.",
+
+	"settings.title":           "Settings:",
+	"settings.help":            "Change a setting with /settings name value.",
+	"settings.changed":         "%s is %s now.",
+	"settings.get.failed":      "Failed to get settings.",
+	"settings.change.failed":   "Failed to change the setting.",
+	"settings.change.denied":   "Not enough rights to change settings.",
+	"settings.invalid":         "%s wasn't changed, %s",
+	"settings.unknown":         "Unknown setting %s, use one of %s.",
+	"settings.hint.timezone":   "use a timezone name like Europe/Berlin.",
+	"settings.hint.language":   "use en, ru or de.",
+	"settings.hint.capacity":   "use a number of participants, 0 means unlimited.",
+	"settings.hint.price":      "use an amount like 10 or 7.50.",
+	"settings.hint.currency":   "use a currency code like EUR.",
+	"settings.hint.guests":     "use on or off.",
+	"settings.hint.waitlist":   "use on or off.",
+	"settings.hint.remove":     "use anyone, inviter or admins.",
+	"settings.hint.reminders":  "use hours before the event like 24,2 or off.",
+	"settings.hint.closing":    "use hours before the kickoff like 6 or off.",
+	"settings.hint.early":      "use hours before registration opens like 24 or off.",
+	"settings.hint.bump":       "use on or off.",
+	"settings.hint.guestlimit": "use a number of guests per participant, 0 means unlimited.",
+
+	"template.usage":       "Usage: /template show, /template set followed by the template or as a reply to a message or a file with it, /template reset.",
+	"template.default":     "Default template:",
+	"template.custom":      "Template of the chat:",
+	"template.get.failed":  "Failed to get the template.",
+	"template.denied":      "Not enough rights to change the template.",
+	"template.invalid":     "Template wasn't saved: %s.",
+	"template.save.failed": "Failed to save the template.",
+	"template.saved":       "Template saved.",
+	"template.reset":       "The default template is used now.",
+
+	"log.title":                 "Recent changes:",
+	"log.empty":                 "No changes yet.",
+	"log.usage":                 "Use /log or /log <number of changes>.",
+	"log.denied":                "Only admins can see the log.",
+	"log.failed":                "Failed to get the log.",
+	"log.action.create":         "%[1]s created %[2]s",
+	"log.action.close":          "%[1]s closed %[2]s",
+	"log.action.add":            "%[1]s added %[2]s",
+	"log.action.remove":         "%[1]s removed %[2]s",
+	"log.action.remove_guests":  "%[1]s removed the guests of %[2]s: %[3]s",
+	"log.action.paid":           "%[1]s marked %[2]s as paid",
+	"log.action.rename":         "%[1]s renamed %[3]s to %[4]s",
+	"log.action.move":           "%[1]s moved %[2]s from %[3]s to %[4]s",
+	"log.action.set_inviter":    "%[1]s changed the inviter of %[2]s from %[3]s to %[4]s",
+	"log.action.setting":        "%[1]s changed %[2]s from %[3]s to %[4]s",
+	"log.action.template":       "%[1]s set a custom event template",
+	"log.action.reset_template": "%[1]s reset the event template",
+	"log.action.grant":          "%[1]s granted %[4]s to %[2]s",
+	"log.action.revoke":         "%[1]s revoked %[3]s from %[2]s",
+	"log.action.undo":           "%[1]s undid the change of %[2]s",
+	"log.action.kickoff":        "%[1]s set the kickoff of %[2]s to %[4]s",
+	"log.action.opens":          "%[1]s set the registration of %[2]s to open at %[4]s",
+	"log.action.close_signup":   "%[1]s closed the registration of %[2]s",
+	"log.action.reopen_signup":  "%[1]s reopened the registration of %[2]s",
+	"log.action.cancel":         "%[1]s cancelled %[2]s",
+	"log.action.reschedule":     "%[1]s rescheduled %[2]s to %[4]s",
+
+	"undo.done":          "Undone: %s.",
+	"undo.nothing":       "There is no change of yours to undo.",
+	"undo.expired.one":   "Changes can be undone within %d minute.",
+	"undo.expired.other": "Changes can be undone within %d minutes.",
+	"undo.conflict":      "The event was changed by someone else since, ask an admin to undo.",
+	"undo.failed":        "Failed to undo the change.",
+
+	"registration.usage":        "Pass a time in the chat timezone like /%[1]s 25.05 18:00, /%[1]s Sat 18:00 or /%[1]s 25.05.2024 18:00.",
+	"registration.kickoff":      "Kickoff: %s.",
+	"registration.opens":        "Registration opens at %s.",
+	"registration.closes":       "Registration closes at %s.",
+	"registration.open":         "Registration is open.",
+	"registration.closed":       "Registration is closed.",
+	"registration.not.open":     "Registration isn't open yet, /event shows when it opens.",
+	"registration.invalid":      "Registration has to open before it closes.",
+	"registration.denied":       "Not enough rights to change the registration times.",
+	"cancel.done":               "%s is cancelled, the participants were told.",
+	"cancel.denied":             "Not enough rights to cancel the event.",
+	"cancel.failed":             "Failed to cancel the event.",
+	"history.title":             "Recent events:",
+	"history.empty":             "No events yet.",
+	"history.usage":             "Use /history or /history <number of events>.",
+	"history.failed":            "Failed to get the events.",
+	"history.event":             "%s %s: %s",
+	"history.status.draft":      "draft",
+	"history.status.open":       "open",
+	"history.status.closed":     "closed",
+	"history.status.cancelled":  "cancelled",
+	"history.status.completed":  "completed",
+	"registration.failed":       "Failed to change the registration times.",
+	"registration.announcement": "Registration is open, sign up with /i!",
+	"reminder.kickoff":          "Reminder: %s starts at %s. Sign up with /i or leave with /cant.",
+
+	"private.open":              "Open the bot in a private chat to see your events and sign up from there.",
+	"private.open.button":       "Open in private chat",
+	"private.dashboard.title":   "Your upcoming events:",
+	"private.dashboard.empty":   "You have no upcoming events. Open the bot with the button under /event in a group to see its events here.",
+	"private.status.none":       "not signed up",
+	"private.status.attending":  "attending as #%d",
+	"private.status.waitlisted": "waitlisted as #%d",
+	"private.balance.due":       "Due for you and your guests: %s.",
+	"private.balance.settled":   "Nothing is due.",
+	"private.hint":              "/i, /cant, /paid and /event sent here change the event of the selected group.",
+	"private.selected":          "Selected %s.",
+	"private.select":            "Select a group first: open the bot with the button under /event in the group.",
+	"private.not.member":        "You are not a member of the group.",
+	"private.failed":            "Failed to get your events.",
+	"private.join.button":       "Join %s",
+	"private.leave.button":      "Leave %s",
+
+	"notifications.button":           "🔔 Notifications",
+	"notifications.title":            "Choose what the bot tells you about in this private chat:",
+	"notifications.failed":           "Failed to change the notifications.",
+	"notifications.topic.waitlist":   "Getting a place from the waitlist",
+	"notifications.topic.event":      "Event cancelled or moved",
+	"notifications.topic.payment":    "Payment reminders",
+	"notifications.topic.membership": "Added or removed by someone else",
+	"notification.promoted":          "%s got a place at %s.",
+	"notification.added":             "%s added you to %s.",
+	"notification.removed":           "%s removed you from %s.",
+	"notification.rescheduled":       "%s moved to %s.",
+	"notification.payment":           "Please pay %s for %s.",
+	"notification.cancelled":         "%s cancelled %s.",
+	"notification.cancelled.reason":  "%s cancelled %s: %s.",
+	"remind.sent.one":                "Reminded %[1]d participant to pay.",
+	"remind.sent.other":              "Reminded %[1]d participants to pay.",
+	"remind.failed":                  "Failed to send the payment reminders.",
+}
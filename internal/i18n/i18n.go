@@ -0,0 +1,121 @@
+// Package i18n translates the bot replies. Messages are looked up by key in the catalog of the language,
+// missing languages and messages fall back to English.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const DefaultLanguage = "en"
+
+// Plural forms, see https://www.unicode.org/cldr/charts/latest/supplemental/language_plural_rules.html
+const (
+	One   = "one"
+	Few   = "few"
+	Many  = "many"
+	Other = "other"
+)
+
+var catalogs = map[string]map[string]string{
+	"en": en,
+	"ru": ru,
+	"de": de,
+}
+
+// Localizer formats the messages in one language.
+type Localizer struct {
+	lang string
+}
+
+// For returns the localizer of the language, the default language if it has no catalog.
+func For(lang string) Localizer {
+	if l, ok := Match(lang); ok {
+		return Localizer{lang: l}
+	}
+	return Localizer{lang: DefaultLanguage}
+}
+
+// Match finds the supported language of a language code like en or ru-RU.
+func Match(code string) (string, bool) {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	_, ok := catalogs[lang]
+	return lang, ok
+}
+
+// Languages returns the languages with a catalog.
+func Languages() []string {
+	var languages []string
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+func (l Localizer) Lang() string {
+	return l.lang
+}
+
+// T formats the message with the key, the key itself is returned when no catalog has it.
+func (l Localizer) T(key string, args ...any) string {
+	format, ok := catalogs[l.lang][key]
+	if !ok {
+		format, ok = catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// N formats the plural form of the message for the count, the count is the first argument of the format.
+// Plural forms are stored as key.one, key.few, key.many and key.other.
+func (l Localizer) N(key string, n int, args ...any) string {
+	args = append([]any{n}, args...)
+	form := key + "." + PluralForm(l.lang, n)
+	if _, ok := catalogs[l.lang][form]; ok {
+		return l.T(form, args...)
+	}
+	return l.T(key+"."+Other, args...)
+}
+
+// PluralForm returns the plural form of the count in the language.
+func PluralForm(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case "ru":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return One
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return Few
+		default:
+			return Many
+		}
+	default:
+		if n == 1 {
+			return One
+		}
+		return Other
+	}
+}
+
+// Plural picks the word for the count from the forms in the order of the language: one and other
+// for English and German, one, few and many for Russian.
+func Plural(lang string, n int, forms ...string) string {
+	if len(forms) == 0 {
+		return ""
+	}
+	idx := 0
+	switch PluralForm(lang, n) {
+	case Few:
+		idx = 1
+	case Many, Other:
+		idx = len(forms) - 1
+	}
+	return forms[min(idx, len(forms)-1)]
+}
@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCatalogs_HaveAllMessages(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for key := range en {
+			if base, _, ok := cutPluralForm(key); ok {
+				assert.Containsf(t, catalog, base+"."+One, "%s has no plural forms of %s", lang, base)
+				continue
+			}
+			assert.Containsf(t, catalog, key, "%s has no message %s", lang, key)
+		}
+	}
+}
+
+func TestCatalogs_CoverSupportedLanguages(t *testing.T) {
+	for _, lang := range model.SupportedLanguages {
+		assert.Containsf(t, catalogs, lang, "No catalog of %s", lang)
+	}
+}
+
+func TestLocalizer_FallsBackToEnglish(t *testing.T) {
+	assert.Equal(t, "en", For("fr").Lang())
+	assert.Equal(t, "ru", For("ru-RU").Lang())
+	assert.Equal(t, "Event created.", For("fr").T("event.created"))
+	assert.Equal(t, "missing.key", For("ru").T("missing.key"))
+}
+
+func TestLocalizer_N(t *testing.T) {
+	assert.Equal(t, "1 role:", For("en").N("roles.list", 1))
+	assert.Equal(t, "3 roles:", For("en").N("roles.list", 3))
+	assert.Equal(t, "21 роль:", For("ru").N("roles.list", 21))
+	assert.Equal(t, "3 роли:", For("ru").N("roles.list", 3))
+	assert.Equal(t, "12 ролей:", For("ru").N("roles.list", 12))
+}
+
+func TestPlural(t *testing.T) {
+	forms := []string{"участник", "участника", "участников"}
+	assert.Equal(t, "участник", Plural("ru", 1, forms...))
+	assert.Equal(t, "участника", Plural("ru", 24, forms...))
+	assert.Equal(t, "участников", Plural("ru", 11, forms...))
+	assert.Equal(t, "участников", Plural("ru", 0, forms...))
+	assert.Equal(t, "participants", Plural("en", 0, "participant", "participants"))
+	assert.Equal(t, "Teilnehmer", Plural("de", 1, "Teilnehmer", "Teilnehmern"))
+}
+
+func cutPluralForm(key string) (string, string, bool) {
+	idx := strings.LastIndex(key, ".")
+	switch form := key[idx+1:]; form {
+	case One, Few, Many, Other:
+		return key[:idx], form, true
+	}
+	return "", "", false
+}
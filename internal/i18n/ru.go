@@ -0,0 +1,206 @@
+package i18n
+
+var ru = map[string]string{
+	"command.unknown":    "Неизвестная команда: %s.",
+	"permissions.failed": "Не удалось проверить права.",
+
+	"event.created":          "Событие создано.",
+	"event.create.failed":    "Не удалось создать событие.",
+	"event.create.denied":    "Событие не создано, недостаточно прав.",
+	"event.get.failed":       "Не удалось получить текущее событие.",
+	"event.full":             "Мест больше нет.",
+	"event.none":             "Событий пока нет, создайте его командой /new.",
+	"event.closed":           "Последнее событие закрыто, создайте новое командой /new.",
+	"event.cancelled":        "Последнее событие %s отменено, создайте новое командой /new.",
+	"event.cancelled.reason": "Последнее событие %s отменено: %s. Создайте новое командой /new.",
+	"guests.not.allowed":     "В этом чате нельзя добавлять гостей.",
+	"guests.limit":           "Больше гостей на это событие пригласить нельзя.",
+	"guests.prompt.one":      "Вы пригласили %[2]s, удалить и гостя?",
+	"guests.prompt.few":      "Вы пригласили %[2]s, удалить и гостей?",
+	"guests.prompt.many":     "Вы пригласили %[2]s, удалить и гостей?",
+	"guests.remove.button":   "Удалить гостей",
+	"guests.keep.button":     "Оставить",
+	"guests.removed":         "Удалены: %s.",
+	"guests.kept":            "Гости остаются в списке.",
+	"guests.none":            "Гостей для удаления нет.",
+	"guests.prompt.denied":   "Ответить может только пригласивший.",
+	"guests.remove.failed":   "Не удалось удалить гостей.",
+
+	"participant.added":              "%s в списке.",
+	"participant.added.by":           "%s добавлен(а), пригласил(а) %s.",
+	"participant.add.failed":         "Не удалось добавить %s.",
+	"participant.removed":            "%s не придёт.",
+	"participant.add.denied":         "Недостаточно прав, чтобы добавить %s, добавьте как гостя: /i Имя.",
+	"participant.unknown":            "%s ещё не писал(а) в чат, добавьте как гостя: /i Имя.",
+	"participant.remove.failed":      "Не удалось удалить %v.",
+	"participant.remove.denied":      "Недостаточно прав, чтобы удалить %s.",
+	"participant.edit.failed":        "Не удалось изменить %v.",
+	"participant.edit.denied":        "Недостаточно прав, чтобы изменить %s.",
+	"participant.rename.usage":       "Использование: /rename N Новое Имя.",
+	"participant.renamed":            "#%d теперь %s.",
+	"participant.name.taken":         "Гость с именем %s уже есть.",
+	"participant.move.usage":         "Использование: /move N M ставит #N на место #M.",
+	"participant.move.denied":        "Передвигать участников вперёд могут только администраторы и организаторы.",
+	"participant.moved":              "%s теперь #%d.",
+	"participant.move.missing":       "#%d или #%d нет в списке.",
+	"participant.inviter.usage":      "Использование: /inviter N @user или ответьте на сообщение пользователя командой /inviter N.",
+	"participant.inviter.changed":    "%s теперь гость %s.",
+	"participant.not.guest":          "#%d - участник чата, пригласившие есть только у гостей.",
+	"participant.number.invalid":     "Неверный номер участника: %s.",
+	"participant.not.found":          "Участник с номером %d не найден.",
+	"participant.guests.one":         "+%d гость",
+	"participant.guests.few":         "+%d гостя",
+	"participant.guests.many":        "+%d гостей",
+	"participant.not.registered":     "%s нет в списке.",
+	"participant.already.registered": "%s уже в списке.",
+	"participant.invited.by":         "(пригласил(а) @%s)",
+
+	"paid.marked":     "%s оплатил(а).",
+	"paid.failed":     "Не удалось отметить оплату.",
+	"paid.failed.for": "Не удалось отметить оплату %v.",
+	"paid.denied":     "Недостаточно прав, чтобы отметить оплату.",
+
+	"role.granted":       "%s теперь %s.",
+	"role.grant.failed":  "Не удалось назначить роль.",
+	"role.grant.denied":  "Недостаточно прав, чтобы назначать роли.",
+	"role.grant.usage":   "Использование: /grant @user owner|organizer|treasurer|member или ответьте на сообщение пользователя командой /grant роль.",
+	"role.revoked":       "%s больше не %s.",
+	"role.none":          "У %s нет роли.",
+	"role.revoke.failed": "Не удалось снять роль.",
+	"role.revoke.denied": "Недостаточно прав, чтобы снимать роли.",
+	"role.revoke.usage":  "Использование: /revoke @user или ответьте на сообщение пользователя командой /revoke.",
+	"roles.list.one":     "%d роль:",
+	"roles.list.few":     "%d роли:",
+	"roles.list.many":    "%d ролей:",
+	"roles.empty":        "Роли не назначены.",
+	"roles.get.failed":   "Не удалось получить роли.",
+
+	"settings.title":           "Настройки:",
+	"settings.help":            "Изменить настройку: /settings имя значение.",
+	"settings.changed":         "%s: теперь %s.",
+	"settings.get.failed":      "Не удалось получить настройки.",
+	"settings.change.failed":   "Не удалось изменить настройку.",
+	"settings.change.denied":   "Недостаточно прав, чтобы менять настройки.",
+	"settings.invalid":         "%s не изменено, %s",
+	"settings.unknown":         "Неизвестная настройка %s, доступны: %s.",
+	"settings.hint.timezone":   "укажите часовой пояс, например Europe/Moscow.",
+	"settings.hint.language":   "укажите en, ru или de.",
+	"settings.hint.capacity":   "укажите число участников, 0 - без ограничений.",
+	"settings.hint.price":      "укажите сумму, например 10 или 7.50.",
+	"settings.hint.currency":   "укажите код валюты, например RUB.",
+	"settings.hint.guests":     "укажите on или off.",
+	"settings.hint.waitlist":   "укажите on или off.",
+	"settings.hint.remove":     "укажите anyone, inviter или admins.",
+	"settings.hint.reminders":  "укажите часы до события, например 24,2, или off.",
+	"settings.hint.closing":    "укажите часы до начала, например 6, или off.",
+	"settings.hint.early":      "укажите часы до открытия записи, например 24, или off.",
+	"settings.hint.bump":       "укажите on или off.",
+	"settings.hint.guestlimit": "укажите число гостей на участника, 0 — без ограничений.",
+
+	"template.usage":       "Использование: /template show, /template set с шаблоном или в ответ на сообщение или файл с шаблоном, /template reset.",
+	"template.default":     "Шаблон по умолчанию:",
+	"template.custom":      "Шаблон чата:",
+	"template.get.failed":  "Не удалось получить шаблон.",
+	"template.denied":      "Недостаточно прав, чтобы менять шаблон.",
+	"template.invalid":     "Шаблон не сохранён: %s.",
+	"template.save.failed": "Не удалось сохранить шаблон.",
+	"template.saved":       "Шаблон сохранён.",
+	"template.reset":       "Теперь используется шаблон по умолчанию.",
+
+	"log.title":                 "Последние изменения:",
+	"log.empty":                 "Изменений пока нет.",
+	"log.usage":                 "Используйте /log или /log <число изменений>.",
+	"log.denied":                "Журнал доступен только администраторам.",
+	"log.failed":                "Не удалось получить журнал.",
+	"log.action.create":         "%[1]s создал(а) %[2]s",
+	"log.action.close":          "%[1]s закрыл(а) %[2]s",
+	"log.action.add":            "%[1]s добавил(а) %[2]s",
+	"log.action.remove":         "%[1]s удалил(а) %[2]s",
+	"log.action.remove_guests":  "%[1]s удалил(а) гостей %[2]s: %[3]s",
+	"log.action.paid":           "%[1]s отметил(а) оплату %[2]s",
+	"log.action.rename":         "%[1]s переименовал(а) %[3]s в %[4]s",
+	"log.action.move":           "%[1]s переместил(а) %[2]s с %[3]s на %[4]s",
+	"log.action.set_inviter":    "%[1]s сменил(а) пригласившего %[2]s с %[3]s на %[4]s",
+	"log.action.setting":        "%[1]s изменил(а) %[2]s с %[3]s на %[4]s",
+	"log.action.template":       "%[1]s установил(а) свой шаблон события",
+	"log.action.reset_template": "%[1]s сбросил(а) шаблон события",
+	"log.action.grant":          "%[1]s выдал(а) %[2]s роль %[4]s",
+	"log.action.revoke":         "%[1]s забрал(а) у %[2]s роль %[3]s",
+	"log.action.undo":           "%[1]s отменил(а) изменение %[2]s",
+	"log.action.kickoff":        "%[1]s назначил(а) начало %[2]s на %[4]s",
+	"log.action.opens":          "%[1]s назначил(а) открытие записи на %[2]s на %[4]s",
+	"log.action.close_signup":   "%[1]s закрыл(а) запись на %[2]s",
+	"log.action.reopen_signup":  "%[1]s снова открыл(а) запись на %[2]s",
+	"log.action.cancel":         "%[1]s отменил(а) %[2]s",
+	"log.action.reschedule":     "%[1]s перенес(ла) %[2]s на %[4]s",
+
+	"undo.done":         "Отменено: %s.",
+	"undo.nothing":      "Нет ваших изменений для отмены.",
+	"undo.expired.one":  "Изменения можно отменить в течение %d минуты.",
+	"undo.expired.few":  "Изменения можно отменить в течение %d минут.",
+	"undo.expired.many": "Изменения можно отменить в течение %d минут.",
+	"undo.conflict":     "После этого событие изменил кто-то другой, попросите администратора отменить.",
+	"undo.failed":       "Не удалось отменить изменение.",
+
+	"registration.usage":        "Укажите время в часовом поясе чата, например /%[1]s 25.05 18:00, /%[1]s Сб 18:00 или /%[1]s 25.05.2024 18:00.",
+	"registration.kickoff":      "Начало: %s.",
+	"registration.opens":        "Запись откроется %s.",
+	"registration.closes":       "Запись закроется %s.",
+	"registration.open":         "Запись открыта.",
+	"registration.closed":       "Запись закрыта.",
+	"registration.not.open":     "Запись ещё не открыта, время открытия есть в /event.",
+	"registration.invalid":      "Запись должна открыться раньше, чем закроется.",
+	"registration.denied":       "Недостаточно прав, чтобы менять время записи.",
+	"cancel.done":               "%s отменено, участники предупреждены.",
+	"cancel.denied":             "Недостаточно прав, чтобы отменить событие.",
+	"cancel.failed":             "Не удалось отменить событие.",
+	"history.title":             "Последние события:",
+	"history.empty":             "Событий пока нет.",
+	"history.usage":             "Используйте /history или /history <количество событий>.",
+	"history.failed":            "Не удалось получить события.",
+	"history.event":             "%s %s: %s",
+	"history.status.draft":      "черновик",
+	"history.status.open":       "открыто",
+	"history.status.closed":     "закрыто",
+	"history.status.cancelled":  "отменено",
+	"history.status.completed":  "завершено",
+	"registration.failed":       "Не удалось изменить время записи.",
+	"registration.announcement": "Запись открыта, записывайтесь командой /i!",
+	"reminder.kickoff":          "Напоминание: %s начинается %s. Записаться — /i, отказаться — /cant.",
+
+	"private.open":              "Откройте бота в личном чате, чтобы видеть свои события и записываться оттуда.",
+	"private.open.button":       "Открыть в личном чате",
+	"private.dashboard.title":   "Ваши предстоящие события:",
+	"private.dashboard.empty":   "Предстоящих событий нет. Откройте бота кнопкой под /event в группе, чтобы видеть здесь её события.",
+	"private.status.none":       "не записаны",
+	"private.status.attending":  "участвуете под №%d",
+	"private.status.waitlisted": "в листе ожидания под №%d",
+	"private.balance.due":       "К оплате за вас и ваших гостей: %s.",
+	"private.balance.settled":   "Долгов нет.",
+	"private.hint":              "Команды /i, /cant, /paid и /event здесь меняют событие выбранной группы.",
+	"private.selected":          "Выбрана группа %s.",
+	"private.select":            "Сначала выберите группу: откройте бота кнопкой под /event в группе.",
+	"private.not.member":        "Вы не состоите в этой группе.",
+	"private.failed":            "Не удалось получить ваши события.",
+	"private.join.button":       "Записаться: %s",
+	"private.leave.button":      "Выписаться: %s",
+
+	"notifications.button":           "🔔 Уведомления",
+	"notifications.title":            "Выберите, о чём бот будет сообщать вам в личном чате:",
+	"notifications.failed":           "Не удалось изменить уведомления.",
+	"notifications.topic.waitlist":   "Место из листа ожидания",
+	"notifications.topic.event":      "Отмена или перенос события",
+	"notifications.topic.payment":    "Напоминания об оплате",
+	"notifications.topic.membership": "Вас записал или выписал кто-то другой",
+	"notification.promoted":          "%s получает место на %s.",
+	"notification.added":             "%s записал вас на %s.",
+	"notification.removed":           "%s выписал вас из %s.",
+	"notification.rescheduled":       "%s перенесено на %s.",
+	"notification.payment":           "Пожалуйста, оплатите %s за %s.",
+	"notification.cancelled":         "%s отменил(а) %s.",
+	"notification.cancelled.reason":  "%s отменил(а) %s: %s.",
+	"remind.sent.one":                "Напоминание об оплате отправлено %[1]d участнику.",
+	"remind.sent.few":                "Напоминание об оплате отправлено %[1]d участникам.",
+	"remind.sent.many":               "Напоминание об оплате отправлено %[1]d участникам.",
+	"remind.failed":                  "Не удалось отправить напоминания об оплате.",
+}
@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"sync"
+	"time"
+)
+
+const namespace = "gorganizer"
+
+// Outcome of a command or an operation.
+const (
+	OutcomeSuccess          = "success"
+	OutcomeError            = "error"
+	OutcomePermissionDenied = "permission_denied"
+)
+
+var (
+	commands = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commands_total",
+		Help:      "Bot commands handled, by command and outcome.",
+	}, []string{"command", "outcome"})
+	commandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time spent handling bot commands, including sending the reply.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command", "outcome"})
+
+	repositoryOps = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "repository_operations_total",
+		Help:      "Repository operations, by operation and outcome.",
+	}, []string{"operation", "outcome"})
+	repositoryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_operation_duration_seconds",
+		Help:      "Latency of repository operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "outcome"})
+
+	telegramRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_requests_total",
+		Help:      "Requests to Telegram Bot API, by method and outcome.",
+	}, []string{"method", "outcome"})
+	telegramDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "telegram_request_duration_seconds",
+		Help:      "Latency of requests to Telegram Bot API.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "outcome"})
+
+	activeEvents = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_events",
+		Help:      "Active events seen by this instance.",
+	})
+	participants = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "participants",
+		Help:      "Participants of the active events seen by this instance.",
+	})
+
+	messageRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "message_retries_total",
+		Help:      "Retried attempts to send a message.",
+	})
+	deadLetters = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "message_dead_letters_total",
+		Help:      "Messages which couldn't be delivered.",
+	})
+
+	updatesBlocked = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_blocked_total",
+		Help:      "Updates that waited for a free slot in a full worker queue.",
+	})
+)
+
+// ObserveCommand records a handled command started at the given time.
+func ObserveCommand(command string, outcome string, start time.Time) {
+	commands.WithLabelValues(command, outcome).Inc()
+	commandDuration.WithLabelValues(command, outcome).Observe(time.Since(start).Seconds())
+}
+
+// ObserveRepositoryOp records a repository operation started at the given time, it's meant to be deferred
+// with a pointer to the named error result.
+func ObserveRepositoryOp(operation string, start time.Time, err *error) {
+	outcome := outcomeOf(*err)
+	repositoryOps.WithLabelValues(operation, outcome).Inc()
+	repositoryDuration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+// ObserveTelegramRequest records a Telegram Bot API call started at the given time.
+func ObserveTelegramRequest(method string, outcome string, start time.Time) {
+	telegramRequests.WithLabelValues(method, outcome).Inc()
+	telegramDuration.WithLabelValues(method, outcome).Observe(time.Since(start).Seconds())
+}
+
+// ObserveUpdateBlocked records an update that waited for a free slot in a worker queue.
+func ObserveUpdateBlocked() {
+	updatesBlocked.Inc()
+}
+
+// RegisterQueueDepth exposes the number of updates waiting in the worker queues.
+func RegisterQueueDepth(depth func() float64) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "update_queue_depth",
+		Help:      "Updates waiting in the worker queues.",
+	}, depth)
+}
+
+// ObserveMessageRetry records a retried attempt to send a message.
+func ObserveMessageRetry() {
+	messageRetries.Inc()
+}
+
+// ObserveDeadLetter records a message which couldn't be delivered.
+func ObserveDeadLetter() {
+	deadLetters.Inc()
+}
+
+// RegisterOutgoingQueueDepth exposes the number of messages waiting to be sent.
+func RegisterOutgoingQueueDepth(depth func() float64) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outgoing_queue_depth",
+		Help:      "Messages waiting to be sent.",
+	}, depth)
+}
+
+// events keeps the participants count of the active event per chat, it backs the events gauges.
+var events = struct {
+	sync.Mutex
+	participants map[int64]int
+}{participants: make(map[int64]int)}
+
+// ObserveEvent updates the events gauges with the current state of a chat event.
+func ObserveEvent(chatId int64, active bool, participantsCount int) {
+	events.Lock()
+	defer events.Unlock()
+	if active {
+		events.participants[chatId] = participantsCount
+	} else {
+		delete(events.participants, chatId)
+	}
+	total := 0
+	for _, count := range events.participants {
+		total += count
+	}
+	activeEvents.Set(float64(len(events.participants)))
+	participants.Set(float64(total))
+}
+
+func outcomeOf(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
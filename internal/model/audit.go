@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Audit actions.
+const (
+	ActionCreate        = "create"
+	ActionClose         = "close"
+	ActionAdd           = "add"
+	ActionRemove        = "remove"
+	ActionRemoveGuests  = "remove_guests"
+	ActionPaid          = "paid"
+	ActionRename        = "rename"
+	ActionMove          = "move"
+	ActionSetInviter    = "set_inviter"
+	ActionSetting       = "setting"
+	ActionTemplate      = "template"
+	ActionResetTemplate = "reset_template"
+	ActionGrant         = "grant"
+	ActionRevoke        = "revoke"
+	ActionUndo          = "undo"
+	ActionKickoff       = "kickoff"
+	ActionOpens         = "opens"
+	ActionCloseSignup   = "close_signup"
+	ActionReopenSignup  = "reopen_signup"
+	ActionCancel        = "cancel"
+	ActionReschedule    = "reschedule"
+)
+
+// AuditEntry records a change of an event or of the chat: who did what to whom, and the value before and after
+// the change. Changes of the chat have no event id.
+type AuditEntry struct {
+	ChatId    int64
+	EventId   string
+	ActorId   int64  `datastore:",noindex"`
+	ActorName string `datastore:",noindex"`
+	Action    string
+	Target    string `datastore:",noindex"`
+	Before    string `datastore:",noindex"`
+	After     string `datastore:",noindex"`
+	// RequestKey identifies the update which made the change, e.g. update-123.
+	RequestKey string `datastore:",noindex"`
+	Time       time.Time
+}
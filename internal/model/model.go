@@ -1,26 +1,64 @@
 package model
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// maxAppliedRequests bounds the number of request keys kept on an event.
+const maxAppliedRequests = 100
+
+// ErrNameTaken is returned when renaming a guest to the name of another guest.
+var ErrNameTaken = errors.New("another guest has the name")
+
+// ErrNotGuest is returned when changing the inviter of a chat member, only guests have inviters.
+var ErrNotGuest = errors.New("participant is not a guest")
+
 type Event struct {
 	ChatId       int64
 	Creator      *Participant
 	Title        string
 	Participants []*Participant `datastore:",noindex"`
 	Created      time.Time
-	Active       bool
+	Status       Status
+	// CancelReason is why the event was cancelled, it may be empty.
+	CancelReason string `datastore:",noindex"`
+	// Capacity limits the attending participants, the rest are waitlisted. 0 means unlimited.
+	Capacity int `datastore:",noindex"`
+	// Price is in minor units of the currency.
+	Price    int64  `datastore:",noindex"`
+	Currency string `datastore:",noindex"`
+	// StartsAt is the kickoff of the event, zero if it wasn't set.
+	StartsAt time.Time `datastore:",noindex"`
+	// RegistrationOpensAt and RegistrationClosesAt limit when participants sign up and leave, zero times
+	// don't limit it.
+	RegistrationOpensAt  time.Time `datastore:",noindex"`
+	RegistrationClosesAt time.Time `datastore:",noindex"`
+	// EarlyAccessHours is how long before registration opens participants with priority may sign up.
+	EarlyAccessHours int `datastore:",noindex"`
+	// Bump lets participants with priority take the places of the ones without it, see Lineup.
+	Bump bool `datastore:",noindex"`
+	// GuestLimit is how many guests each participant may invite, 0 means unlimited.
+	GuestLimit int `datastore:",noindex"`
+	// AppliedRequests keeps the keys of the latest requests that changed the event.
+	AppliedRequests []string `datastore:",noindex"`
 }
 
 type Participant struct {
-	Number        int
-	Name          string
-	TelegramId    *int64
+	Number     int
+	Name       string
+	TelegramId *int64
+	// Username is the Telegram username of the participant without @, empty for guests.
+	Username      string
 	InvitedBy     *Participant
 	PaymentStatus PaymentStatus
+	// Priority is set for the members of the chat when they join, guests never have it.
+	Priority bool
 }
 
 type PaymentStatus struct {
@@ -71,6 +109,103 @@ func (e *Event) AddParticipant(participant *Participant) bool {
 	return false
 }
 
+// MergeGuest links a guest entry standing for the participant to the participant, keeping its number and payment:
+// a guest added by the @username of the participant or one the participant invited under their name. Guests of
+// others who merely share the name are kept. It returns the merged entry, nil if there is no such guest.
+func (e *Event) MergeGuest(participant *Participant) *Participant {
+	if participant.TelegramId == nil {
+		return nil
+	}
+	for _, p := range e.Participants {
+		if p.TelegramId == nil && participant.standsFor(p) {
+			p.TelegramId = participant.TelegramId
+			p.Name = participant.Name
+			p.Username = participant.Username
+			p.InvitedBy = nil
+			return p
+		}
+	}
+	return nil
+}
+
+// standsFor checks if the guest is the participant: it was added by the @username of the participant, or by
+// the participant under their name.
+func (p Participant) standsFor(guest *Participant) bool {
+	name := strings.TrimSpace(guest.Name)
+	if strings.HasPrefix(name, "@") && p.Username != "" && strings.EqualFold(name[1:], p.Username) {
+		return true
+	}
+	inviter := guest.InvitedBy
+	return inviter != nil && inviter.TelegramId != nil && *inviter.TelegramId == *p.TelegramId && p.isCalled(name)
+}
+
+// isCalled checks if the name refers to the participant, by the name itself or the @username.
+func (p Participant) isCalled(name string) bool {
+	name = strings.TrimSpace(name)
+	if strings.EqualFold(name, p.Name) {
+		return true
+	}
+	return p.Username != "" && strings.EqualFold(strings.TrimPrefix(name, "@"), p.Username)
+}
+
+// RenameParticipant changes the name of the participant with the number, it returns the renamed participant
+// or nil if there is no such participant. Guests are identified by the name, so it has to be unique among them.
+func (e *Event) RenameParticipant(number int, name string) (*Participant, error) {
+	p := e.FindParticipantByNumber(number)
+	if p == nil {
+		return nil, nil
+	}
+	if p.TelegramId == nil {
+		if other := e.FindParticipant(name); other != nil && other != p {
+			return nil, ErrNameTaken
+		}
+	}
+	p.Name = name
+	return p, nil
+}
+
+// MoveParticipant puts the participant with the number to the position of the participant with the other number.
+// The numbers in use stay the same, the participants in between are shifted. It returns the moved participant
+// or nil if either number isn't in use.
+func (e *Event) MoveParticipant(number int, to int) *Participant {
+	from, toIdx := -1, -1
+	numbers := make([]int, len(e.Participants))
+	for idx, p := range e.Participants {
+		numbers[idx] = p.Number
+		if p.Number == number {
+			from = idx
+		}
+		if p.Number == to {
+			toIdx = idx
+		}
+	}
+	if from < 0 || toIdx < 0 {
+		return nil
+	}
+	moved := e.Participants[from]
+	e.Participants = append(e.Participants[:from], e.Participants[from+1:]...)
+	e.Participants = append(e.Participants[:toIdx], append([]*Participant{moved}, e.Participants[toIdx:]...)...)
+	sort.Ints(numbers)
+	for idx, p := range e.Participants {
+		p.Number = numbers[idx]
+	}
+	return moved
+}
+
+// SetInviter changes the inviter of the guest with the number, it returns the guest or nil if there is no such
+// participant.
+func (e *Event) SetInviter(number int, inviter *Participant) (*Participant, error) {
+	p := e.FindParticipantByNumber(number)
+	if p == nil {
+		return nil, nil
+	}
+	if p.TelegramId != nil {
+		return nil, ErrNotGuest
+	}
+	p.InvitedBy = inviter
+	return p, nil
+}
+
 func (e *Event) RemoveParticipant(id string) *Participant {
 	for idx, p := range e.Participants {
 		if p.Id() == id {
@@ -80,6 +215,22 @@ func (e *Event) RemoveParticipant(id string) *Participant {
 	return nil
 }
 
+// GuestsOf returns the guests invited by the participant with the id, in the order they joined.
+func (e *Event) GuestsOf(inviterId string) []*Participant {
+	var guests []*Participant
+	for _, p := range e.Participants {
+		if p.InvitedBy != nil && p.InvitedBy.Id() == inviterId {
+			guests = append(guests, p)
+		}
+	}
+	return guests
+}
+
+// CanInvite checks if the participant with the id may invite one more guest.
+func (e *Event) CanInvite(inviterId string) bool {
+	return e.GuestLimit <= 0 || len(e.GuestsOf(inviterId)) < e.GuestLimit
+}
+
 func (e *Event) RemoveParticipantByNumber(number int) *Participant {
 	for idx, p := range e.Participants {
 		if p.Number == number {
@@ -89,20 +240,138 @@ func (e *Event) RemoveParticipantByNumber(number int) *Participant {
 	return nil
 }
 
-func (e *Event) MarkPaid(id string) {
+// MarkPaid marks the participant as paid, it returns nil if there is no such participant.
+func (e *Event) MarkPaid(id string) *Participant {
 	for _, p := range e.Participants {
 		if p.Id() == id {
 			p.PaymentStatus = PaymentStatus{Paid: true}
+			return p
 		}
 	}
+	return nil
 }
 
-func (e *Event) MarkPaidByNumber(number int) {
+// MarkPaidByNumber marks the participant with the number as paid, it returns nil if there is no such participant.
+func (e *Event) MarkPaidByNumber(number int) *Participant {
 	for _, p := range e.Participants {
 		if p.Number == number {
 			p.PaymentStatus = PaymentStatus{Paid: true}
+			return p
+		}
+	}
+	return nil
+}
+
+// Snapshot copies the participants, so they can be restored after the event is changed.
+func (e *Event) Snapshot() []*Participant {
+	participants := make([]*Participant, 0, len(e.Participants))
+	for _, p := range e.Participants {
+		copied := *p
+		if p.InvitedBy != nil {
+			inviter := *p.InvitedBy
+			copied.InvitedBy = &inviter
 		}
+		participants = append(participants, &copied)
+	}
+	return participants
+}
+
+// Restore replaces the participants with a snapshot.
+func (e *Event) Restore(participants []*Participant) {
+	e.Participants = participants
+}
+
+// IsFull checks if a new participant would be waitlisted.
+func (e *Event) IsFull() bool {
+	return e.Capacity > 0 && len(e.Participants) >= e.Capacity
+}
+
+// HasPlaceFor checks if the new participant would attend rather than be waitlisted.
+func (e *Event) HasPlaceFor(participant *Participant) bool {
+	if !e.IsFull() {
+		return true
+	}
+	candidate := *participant
+	trial := Event{Capacity: e.Capacity, Bump: e.Bump, Participants: append(e.Participants[:len(e.Participants):len(e.Participants)], &candidate)}
+	attending, _ := trial.Lineup()
+	return slices.Contains(attending, &candidate)
+}
+
+// Lineup splits the participants into attending ones and the waitlist, both in the order they joined.
+// The places go to the participants by rank: with bumping the ones with priority first, then the other chat
+// members, then guests, and the earliest joined first within a rank. So one with priority joining late pushes
+// the latest joined one without priority to the waitlist, a member joining late pushes the latest joined guest,
+// participants of a rank never push each other, and a freed place goes to the waitlisted participant of the best
+// rank.
+func (e *Event) Lineup() (attending []*Participant, waitlist []*Participant) {
+	if e.Capacity <= 0 || len(e.Participants) <= e.Capacity {
+		return e.Participants, nil
+	}
+	var places [rankCount]int
+	for _, p := range e.Participants {
+		places[e.rank(p)]++
+	}
+	free := e.Capacity
+	for r := range places {
+		places[r] = min(places[r], free)
+		free -= places[r]
+	}
+	for _, p := range e.Participants {
+		if r := e.rank(p); places[r] > 0 {
+			places[r]--
+			attending = append(attending, p)
+		} else {
+			waitlist = append(waitlist, p)
+		}
+	}
+	return attending, waitlist
+}
+
+// Ranks of the participants getting the places of an event, the lower the earlier.
+const (
+	rankPriority = iota
+	rankMember
+	rankGuest
+	rankCount
+)
+
+func (e *Event) rank(p *Participant) int {
+	switch {
+	case p.InvitedBy != nil:
+		return rankGuest
+	case e.Bump && p.Priority:
+		return rankPriority
+	}
+	return rankMember
+}
+
+// IsApplied checks if the request with the key already changed the event, an empty key is never applied.
+func (e *Event) IsApplied(requestKey string) bool {
+	if requestKey == "" {
+		return false
+	}
+	for _, key := range e.AppliedRequests {
+		if key == requestKey {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkApplied records the request key, it returns false if the key was already applied.
+// Only the latest keys are kept.
+func (e *Event) MarkApplied(requestKey string) bool {
+	if requestKey == "" {
+		return true
+	}
+	if e.IsApplied(requestKey) {
+		return false
+	}
+	e.AppliedRequests = append(e.AppliedRequests, requestKey)
+	if len(e.AppliedRequests) > maxAppliedRequests {
+		e.AppliedRequests = e.AppliedRequests[len(e.AppliedRequests)-maxAppliedRequests:]
 	}
+	return true
 }
 
 func (e *Event) removeParticipantByIndex(idx int) *Participant {
@@ -14,7 +14,7 @@ func TestEvent_AddParticipant(t *testing.T) {
 		Title:        "Football",
 		Participants: make([]*Participant, 0),
 		Created:      time.Now(),
-		Active:       true,
+		Status:       StatusOpen,
 	}
 
 	participant := &Participant{
@@ -34,7 +34,7 @@ func TestEvent_AddSameParticipantTwice(t *testing.T) {
 		Title:        "Football",
 		Participants: make([]*Participant, 0),
 		Created:      time.Now(),
-		Active:       true,
+		Status:       StatusOpen,
 	}
 
 	newParticipant := &Participant{
@@ -55,7 +55,7 @@ func TestEvent_AddDifferentParticipants(t *testing.T) {
 		Title:        "Football",
 		Participants: make([]*Participant, 0),
 		Created:      time.Now(),
-		Active:       true,
+		Status:       StatusOpen,
 	}
 
 	p1 := &Participant{
@@ -82,7 +82,7 @@ func TestEvent_RemoveParticipant(t *testing.T) {
 		Title:        "Football",
 		Participants: make([]*Participant, 0),
 		Created:      time.Now(),
-		Active:       true,
+		Status:       StatusOpen,
 	}
 
 	participant := &Participant{
@@ -104,7 +104,7 @@ func TestEvent_RemoveParticipantWhenMany(t *testing.T) {
 		Title:        "Football",
 		Participants: make([]*Participant, 0),
 		Created:      time.Now(),
-		Active:       true,
+		Status:       StatusOpen,
 	}
 
 	p1 := &Participant{
@@ -141,7 +141,7 @@ func TestEvent_RemoveParticipantByIndex(t *testing.T) {
 		Title:        "Football",
 		Participants: make([]*Participant, 0),
 		Created:      time.Now(),
-		Active:       true,
+		Status:       StatusOpen,
 	}
 
 	p1 := &Participant{
@@ -178,7 +178,7 @@ func TestEvent_FindParticipant(t *testing.T) {
 		Title:        "Football",
 		Participants: make([]*Participant, 0),
 		Created:      time.Now(),
-		Active:       true,
+		Status:       StatusOpen,
 	}
 
 	event.AddParticipant(&Participant{
@@ -228,8 +228,10 @@ func TestMarkPaid(t *testing.T) {
 		},
 	}
 
-	e.MarkPaid(e.Participants[1].Id())
+	paid := e.MarkPaid(e.Participants[1].Id())
 	assert.Equal(t, PaymentStatus{Paid: true}, e.Participants[1].PaymentStatus)
+	assert.Equal(t, e.Participants[1], paid)
+	assert.Nil(t, e.MarkPaid("unknown"))
 }
 
 func TestMarkPaidByNumber(t *testing.T) {
@@ -255,10 +257,241 @@ func TestMarkPaidByNumber(t *testing.T) {
 		},
 	}
 
-	e.MarkPaidByNumber(e.Participants[1].Number)
+	paid := e.MarkPaidByNumber(e.Participants[1].Number)
 	assert.Equal(t, PaymentStatus{Paid: true}, e.Participants[1].PaymentStatus)
+	assert.Equal(t, e.Participants[1], paid)
+	assert.Nil(t, e.MarkPaidByNumber(4))
+}
+
+func TestEvent_MarkApplied(t *testing.T) {
+	e := &Event{}
+
+	assert.True(t, e.MarkApplied("update-1"), "First request wasn't applied")
+	assert.False(t, e.MarkApplied("update-1"), "Same request applied twice")
+	assert.True(t, e.MarkApplied("update-2"), "Another request wasn't applied")
+	assert.True(t, e.IsApplied("update-1"))
+	assert.False(t, e.IsApplied(""), "Empty key is applied")
+	assert.True(t, e.MarkApplied(""), "Request without a key wasn't applied")
+}
+
+func TestEvent_MarkAppliedKeepsLatestKeys(t *testing.T) {
+	e := &Event{}
+	for i := 0; i <= maxAppliedRequests; i++ {
+		e.MarkApplied("update-" + strconv.Itoa(i))
+	}
+
+	assert.Len(t, e.AppliedRequests, maxAppliedRequests)
+	assert.False(t, e.IsApplied("update-0"), "The oldest key wasn't dropped")
+	assert.True(t, e.IsApplied("update-"+strconv.Itoa(maxAppliedRequests)))
+}
+
+func TestEvent_MergeGuest(t *testing.T) {
+	e := &Event{}
+	inviter := &Participant{Name: "Alice", TelegramId: getIntPointer(111)}
+	e.AddParticipant(inviter)
+	e.AddParticipant(&Participant{Name: "@Bob", InvitedBy: inviter, PaymentStatus: PaymentStatus{Paid: true}})
+
+	merged := e.MergeGuest(&Participant{Name: "Bob Smith", Username: "bob", TelegramId: getIntPointer(222)})
+
+	assert.NotNil(t, merged)
+	assert.Len(t, e.Participants, 2)
+	assert.Equal(t, 2, merged.Number)
+	assert.Equal(t, "Bob Smith", merged.Name)
+	assert.Equal(t, "222", merged.Id())
+	assert.True(t, merged.PaymentStatus.Paid, "Payment wasn't kept")
+	assert.Nil(t, merged.InvitedBy)
+	assert.Nil(t, e.MergeGuest(&Participant{Name: "Charlie", TelegramId: getIntPointer(333)}))
+	assert.Nil(t, e.MergeGuest(&Participant{Name: "Alice"}), "Guest merged into a guest")
+}
+
+func TestEvent_MergeGuestKeepsNamesakesOfOthers(t *testing.T) {
+	e := &Event{}
+	alice := &Participant{Name: "Alice", TelegramId: getIntPointer(111)}
+	charlie := &Participant{Name: "Charlie", TelegramId: getIntPointer(333)}
+	e.AddParticipant(alice)
+	e.AddParticipant(charlie)
+	e.AddParticipant(&Participant{Name: "Bob", InvitedBy: alice, PaymentStatus: PaymentStatus{Paid: true}})
+	e.AddParticipant(&Participant{Name: "bob", InvitedBy: charlie})
+
+	assert.Nil(t, e.MergeGuest(&Participant{Name: "Bob", TelegramId: getIntPointer(222)}), "Guest of another inviter merged")
+
+	e.AddParticipant(&Participant{Name: "Dave", InvitedBy: &Participant{Name: "Dave", TelegramId: getIntPointer(444)}})
+	merged := e.MergeGuest(&Participant{Name: "Dave", TelegramId: getIntPointer(444)})
+	assert.NotNil(t, merged, "Own guest wasn't merged")
+	assert.Equal(t, 5, merged.Number)
+	assert.Nil(t, merged.InvitedBy)
+}
+
+func TestEvent_RenameParticipant(t *testing.T) {
+	e := &Event{}
+	e.AddParticipant(&Participant{Name: "Bob"})
+	e.AddParticipant(&Participant{Name: "Charly"})
+
+	renamed, err := e.RenameParticipant(2, "Charlie")
+	assert.NoError(t, err)
+	assert.Equal(t, "Charlie", renamed.Name)
+	assert.Equal(t, 2, renamed.Number)
+
+	_, err = e.RenameParticipant(2, "Bob")
+	assert.ErrorIs(t, err, ErrNameTaken)
+	renamed, err = e.RenameParticipant(3, "Dave")
+	assert.NoError(t, err)
+	assert.Nil(t, renamed)
+}
+
+func TestEvent_MoveParticipant(t *testing.T) {
+	e := &Event{}
+	for _, name := range []string{"Alice", "Bob", "Charlie", "Dave"} {
+		e.AddParticipant(&Participant{Name: name})
+	}
+	e.RemoveParticipantByNumber(3)
+
+	moved := e.MoveParticipant(4, 1)
+
+	assert.Equal(t, "Dave", moved.Name)
+	var names []string
+	var numbers []int
+	for _, p := range e.Participants {
+		names = append(names, p.Name)
+		numbers = append(numbers, p.Number)
+	}
+	assert.Equal(t, []string{"Dave", "Alice", "Bob"}, names)
+	assert.Equal(t, []int{1, 2, 4}, numbers, "Numbers in use changed")
+
+	moved = e.MoveParticipant(1, 4)
+	assert.Equal(t, "Dave", moved.Name)
+	assert.Equal(t, 4, moved.Number)
+	assert.Nil(t, e.MoveParticipant(3, 1), "Moved a missing participant")
+}
+
+func TestEvent_SetInviter(t *testing.T) {
+	e := &Event{}
+	alice := &Participant{Name: "Alice", TelegramId: getIntPointer(111)}
+	bob := &Participant{Name: "Bob", TelegramId: getIntPointer(222)}
+	e.AddParticipant(alice)
+	e.AddParticipant(&Participant{Name: "Guest", InvitedBy: alice})
+
+	guest, err := e.SetInviter(2, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, bob, guest.InvitedBy)
+
+	_, err = e.SetInviter(1, bob)
+	assert.ErrorIs(t, err, ErrNotGuest)
+}
+
+func TestEvent_Lineup(t *testing.T) {
+	e := &Event{Capacity: 2}
+	for _, name := range []string{"Alice", "Bob", "Charlie"} {
+		e.AddParticipant(&Participant{Name: name})
+	}
+
+	attending, waitlist := e.Lineup()
+	assert.Len(t, attending, 2)
+	assert.Len(t, waitlist, 1)
+	assert.Equal(t, "Charlie", waitlist[0].Name)
+	assert.True(t, e.IsFull())
+
+	e.Capacity = 0
+	attending, waitlist = e.Lineup()
+	assert.Len(t, attending, 3)
+	assert.Empty(t, waitlist)
+	assert.False(t, e.IsFull(), "Event without capacity is full")
+}
+
+func TestEvent_LineupBumpsLatestWithoutPriority(t *testing.T) {
+	e := &Event{Capacity: 3, Bump: true}
+	for _, p := range []*Participant{{Name: "Alice"}, {Name: "Bob", Priority: true}, {Name: "Charlie"}} {
+		e.AddParticipant(p)
+	}
+	assert.True(t, e.HasPlaceFor(&Participant{Name: "Gina", Priority: true}))
+	assert.False(t, e.HasPlaceFor(&Participant{Name: "Gina"}))
+
+	e.AddParticipant(&Participant{Name: "Dave", Priority: true})
+	attending, waitlist := e.Lineup()
+	assert.Equal(t, []string{"Alice", "Bob", "Dave"}, names(attending), "Attending aren't in the order they joined")
+	assert.Equal(t, []string{"Charlie"}, names(waitlist), "The latest joined without priority isn't bumped")
+
+	e.AddParticipant(&Participant{Name: "Eve", Priority: true})
+	e.AddParticipant(&Participant{Name: "Frank", Priority: true})
+	assert.False(t, e.HasPlaceFor(&Participant{Name: "Gina", Priority: true}))
+	attending, waitlist = e.Lineup()
+	assert.Equal(t, []string{"Bob", "Dave", "Eve"}, names(attending))
+	assert.Equal(t, []string{"Alice", "Charlie", "Frank"}, names(waitlist), "Priority participants pushed each other")
+
+	e.RemoveParticipantByNumber(2)
+	attending, waitlist = e.Lineup()
+	assert.Equal(t, []string{"Dave", "Eve", "Frank"}, names(attending), "The freed place didn't go to priority first")
+	assert.Equal(t, []string{"Alice", "Charlie"}, names(waitlist))
+
+	e.Bump = false
+	attending, _ = e.Lineup()
+	assert.Equal(t, []string{"Alice", "Charlie", "Dave"}, names(attending), "Priority applied without bumping")
+}
+
+func TestEvent_LineupPutsGuestsAfterMembers(t *testing.T) {
+	e := &Event{Capacity: 3, Bump: true}
+	alice := &Participant{Name: "Alice", TelegramId: getIntPointer(1)}
+	e.AddParticipant(alice)
+	e.AddParticipant(&Participant{Name: "Bob", InvitedBy: alice})
+	e.AddParticipant(&Participant{Name: "Charlie", TelegramId: getIntPointer(3)})
+	assert.True(t, e.HasPlaceFor(&Participant{Name: "Dave", TelegramId: getIntPointer(4)}))
+	assert.False(t, e.HasPlaceFor(&Participant{Name: "Eve", InvitedBy: alice}))
+
+	e.AddParticipant(&Participant{Name: "Dave", TelegramId: getIntPointer(4)})
+	e.AddParticipant(&Participant{Name: "Eve", TelegramId: getIntPointer(5), Priority: true})
+	attending, waitlist := e.Lineup()
+	assert.Equal(t, []string{"Alice", "Charlie", "Eve"}, names(attending))
+	assert.Equal(t, []string{"Bob", "Dave"}, names(waitlist), "Guests aren't waitlisted first")
+}
+
+func TestEvent_GuestsOf(t *testing.T) {
+	e := &Event{GuestLimit: 2}
+	alice := &Participant{Name: "Alice", TelegramId: getIntPointer(1)}
+	e.AddParticipant(alice)
+	e.AddParticipant(&Participant{Name: "Bob", InvitedBy: alice})
+	e.AddParticipant(&Participant{Name: "Charlie", InvitedBy: &Participant{Name: "Dave", TelegramId: getIntPointer(4)}})
+	assert.Equal(t, []string{"Bob"}, names(e.GuestsOf(alice.Id())))
+	assert.True(t, e.CanInvite(alice.Id()))
+
+	e.AddParticipant(&Participant{Name: "Eve", InvitedBy: alice})
+	assert.False(t, e.CanInvite(alice.Id()), "Guest limit isn't applied")
+	assert.True(t, e.CanInvite("4"))
+
+	e.GuestLimit = 0
+	assert.True(t, e.CanInvite(alice.Id()), "Unlimited guests are limited")
+}
+
+func TestEvent_SnapshotRestore(t *testing.T) {
+	e := &Event{}
+	alice := &Participant{Name: "Alice", TelegramId: getIntPointer(111)}
+	e.AddParticipant(alice)
+	e.AddParticipant(&Participant{Name: "Bob", InvitedBy: alice})
+	e.AddParticipant(&Participant{Name: "Charlie"})
+	e.MarkPaidByNumber(2)
+
+	snapshot := e.Snapshot()
+	e.RemoveParticipantByNumber(2)
+	e.MarkPaidByNumber(3)
+	_, err := e.RenameParticipant(1, "Alicia")
+	assert.NoError(t, err)
+
+	e.Restore(snapshot)
+	assert.Len(t, e.Participants, 3)
+	assert.Equal(t, "Alice", e.Participants[0].Name)
+	assert.Equal(t, 2, e.Participants[1].Number)
+	assert.True(t, e.Participants[1].PaymentStatus.Paid)
+	assert.Equal(t, "Alice", e.Participants[1].InvitedBy.Name)
+	assert.False(t, e.Participants[2].PaymentStatus.Paid)
 }
 
 func getIntPointer(id int64) *int64 {
 	return &id
 }
+
+func names(participants []*Participant) []string {
+	var result []string
+	for _, p := range participants {
+		result = append(result, p.Name)
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+package model
+
+import "slices"
+
+// NotificationKind is what happened to the recipient of a notification.
+type NotificationKind string
+
+const (
+	// NotificationPromoted is a participant or a guest getting a place from the waitlist.
+	NotificationPromoted    NotificationKind = "promoted"
+	NotificationAdded       NotificationKind = "added"
+	NotificationRemoved     NotificationKind = "removed"
+	NotificationRescheduled NotificationKind = "rescheduled"
+	NotificationCancelled   NotificationKind = "cancelled"
+	NotificationPaymentDue  NotificationKind = "payment_due"
+)
+
+// NotificationTopic groups the kinds of notifications users opt in to.
+type NotificationTopic string
+
+const (
+	TopicWaitlist NotificationTopic = "waitlist"
+	// TopicEvent is the event being cancelled or moved.
+	TopicEvent   NotificationTopic = "event"
+	TopicPayment NotificationTopic = "payment"
+	// TopicMembership is being added to or removed from an event by someone else.
+	TopicMembership NotificationTopic = "membership"
+)
+
+var NotificationTopics = []NotificationTopic{TopicWaitlist, TopicEvent, TopicPayment, TopicMembership}
+
+// Topic returns the topic users opt in to for the kind.
+func (k NotificationKind) Topic() NotificationTopic {
+	switch k {
+	case NotificationPromoted:
+		return TopicWaitlist
+	case NotificationRescheduled, NotificationCancelled:
+		return TopicEvent
+	case NotificationPaymentDue:
+		return TopicPayment
+	}
+	return TopicMembership
+}
+
+// Notification is a private message to a user about an event of a group.
+type Notification struct {
+	UserId int64
+	Kind   NotificationKind
+	ChatId int64
+	Event  string
+	// Participant is the name of the participant it's about, the guest when the inviter is notified.
+	Participant string
+	// Actor is the name of who made the change, empty for changes by the bot.
+	Actor string
+	// Details is the new kickoff, the amount due formatted for the chat, or the reason of cancelling.
+	Details string
+}
+
+// NotificationPreferences are the topics the user opted in to, nothing is sent by default.
+type NotificationPreferences struct {
+	UserId int64
+	Topics []NotificationTopic `datastore:",noindex"`
+	// Language is the language of the Telegram app of the user when the preferences were changed.
+	Language string `datastore:",noindex"`
+}
+
+// Allows checks if the user opted in to the notifications of the kind.
+func (p *NotificationPreferences) Allows(kind NotificationKind) bool {
+	return slices.Contains(p.Topics, kind.Topic())
+}
+
+// Toggle opts in to the topic or out of it, unknown topics are ignored.
+func (p *NotificationPreferences) Toggle(topic NotificationTopic) {
+	if !slices.Contains(NotificationTopics, topic) {
+		return
+	}
+	if idx := slices.Index(p.Topics, topic); idx >= 0 {
+		p.Topics = slices.Delete(p.Topics, idx, idx+1)
+	} else {
+		p.Topics = append(p.Topics, topic)
+	}
+}
+
+// Notifications returns what the change of the participants by the actor means to others: participants getting
+// a place from the waitlist and chat members added or removed by someone else. The inviter is notified about
+// a guest getting a place.
+func (e *Event) Notifications(before []*Participant, actor ChatUser) []Notification {
+	previous := Event{Capacity: e.Capacity, Bump: e.Bump, Participants: before}
+	_, waitlist := previous.Lineup()
+	attending, _ := e.Lineup()
+	var notifications []Notification
+	notify := func(userId *int64, kind NotificationKind, p *Participant) {
+		if userId == nil || *userId == actor.UserId {
+			return
+		}
+		notifications = append(notifications, Notification{
+			UserId: *userId, Kind: kind, ChatId: e.ChatId, Event: e.Title, Participant: p.Name, Actor: actor.Mention(),
+		})
+	}
+	for _, p := range attending {
+		if slices.ContainsFunc(waitlist, func(w *Participant) bool { return w.Id() == p.Id() }) {
+			notify(recipientOf(p), NotificationPromoted, p)
+		}
+	}
+	for _, p := range e.Participants {
+		if !slices.ContainsFunc(before, func(b *Participant) bool { return b.Id() == p.Id() }) {
+			notify(p.TelegramId, NotificationAdded, p)
+		}
+	}
+	for _, p := range before {
+		if e.FindParticipant(p.Id()) == nil {
+			notify(p.TelegramId, NotificationRemoved, p)
+		}
+	}
+	return notifications
+}
+
+// NotifyParticipants returns the notification of the kind for every chat member taking part but the actor.
+func (e *Event) NotifyParticipants(kind NotificationKind, actor ChatUser, details string) []Notification {
+	var notifications []Notification
+	for _, p := range e.Participants {
+		if p.TelegramId == nil || *p.TelegramId == actor.UserId {
+			continue
+		}
+		notifications = append(notifications, Notification{
+			UserId: *p.TelegramId, Kind: kind, ChatId: e.ChatId, Event: e.Title, Participant: p.Name, Actor: actor.Mention(), Details: details,
+		})
+	}
+	return notifications
+}
+
+// PaymentReminders returns a reminder for every user who owes for the event, see Due.
+func (e *Event) PaymentReminders() []Notification {
+	var notifications []Notification
+	var reminded []int64
+	attending, _ := e.Lineup()
+	for _, p := range attending {
+		userId := recipientOf(p)
+		if userId == nil || slices.Contains(reminded, *userId) {
+			continue
+		}
+		reminded = append(reminded, *userId)
+		if due := e.Due(*userId); due > 0 {
+			name := p.Name
+			if p.InvitedBy != nil {
+				name = p.InvitedBy.Name
+			}
+			notifications = append(notifications, Notification{
+				UserId: *userId, Kind: NotificationPaymentDue, ChatId: e.ChatId, Event: e.Title, Participant: name,
+				Details: FormatPrice(due, e.Currency),
+			})
+		}
+	}
+	return notifications
+}
+
+// recipientOf returns the user told about the participant: the participant, or the inviter of a guest.
+func recipientOf(p *Participant) *int64 {
+	if p.TelegramId != nil {
+		return p.TelegramId
+	}
+	if p.InvitedBy != nil {
+		return p.InvitedBy.TelegramId
+	}
+	return nil
+}
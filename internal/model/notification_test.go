@@ -0,0 +1,64 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestEvent_Notifications(t *testing.T) {
+	aliceId, bobId, charlieId := int64(1), int64(2), int64(3)
+	alice := &Participant{Name: "Alice", TelegramId: &aliceId}
+	bob := &Participant{Name: "Bob", TelegramId: &bobId}
+	event := Event{ChatId: -1, Title: "Football", Capacity: 2}
+	event.AddParticipant(alice)
+	event.AddParticipant(bob)
+	event.AddParticipant(&Participant{Name: "Guest", InvitedBy: alice})
+	organizer := ChatUser{UserId: 9, Username: "organizer"}
+
+	before := event.Snapshot()
+	event.RemoveParticipant(bob.Id())
+	assert.ElementsMatch(t, []Notification{
+		{UserId: aliceId, Kind: NotificationPromoted, ChatId: -1, Event: "Football", Participant: "Guest", Actor: "@organizer"},
+		{UserId: bobId, Kind: NotificationRemoved, ChatId: -1, Event: "Football", Participant: "Bob", Actor: "@organizer"},
+	}, event.Notifications(before, organizer))
+
+	before = event.Snapshot()
+	event.AddParticipant(&Participant{Name: "Charlie", TelegramId: &charlieId})
+	assert.Equal(t, []Notification{
+		{UserId: charlieId, Kind: NotificationAdded, ChatId: -1, Event: "Football", Participant: "Charlie", Actor: "@organizer"},
+	}, event.Notifications(before, organizer), "The guest pushed to the waitlist isn't notified")
+
+	before = event.Snapshot()
+	event.RemoveParticipant(strconv.FormatInt(charlieId, 10))
+	assert.Equal(t, []Notification{
+		{UserId: aliceId, Kind: NotificationPromoted, ChatId: -1, Event: "Football", Participant: "Guest", Actor: "@charlie"},
+	}, event.Notifications(before, ChatUser{UserId: charlieId, Username: "charlie"}), "Leaving yourself notifies only the inviter of the guest getting the place")
+}
+
+func TestEvent_PaymentReminders(t *testing.T) {
+	aliceId, bobId := int64(1), int64(2)
+	alice := &Participant{Name: "Alice", TelegramId: &aliceId}
+	event := Event{ChatId: -1, Title: "Football", Price: 1000, Currency: "EUR"}
+	event.AddParticipant(alice)
+	event.AddParticipant(&Participant{Name: "Guest", InvitedBy: alice})
+	event.AddParticipant(&Participant{Name: "Bob", TelegramId: &bobId, PaymentStatus: PaymentStatus{Paid: true}})
+
+	assert.Equal(t, []Notification{
+		{UserId: aliceId, Kind: NotificationPaymentDue, ChatId: -1, Event: "Football", Participant: "Alice", Details: "20.00 EUR"},
+	}, event.PaymentReminders())
+}
+
+func TestNotificationPreferences_Toggle(t *testing.T) {
+	preferences := NotificationPreferences{}
+	assert.False(t, preferences.Allows(NotificationPromoted), "Nothing is sent by default")
+
+	preferences.Toggle(TopicWaitlist)
+	preferences.Toggle("unknown")
+	assert.True(t, preferences.Allows(NotificationPromoted))
+	assert.False(t, preferences.Allows(NotificationAdded))
+	assert.Equal(t, []NotificationTopic{TopicWaitlist}, preferences.Topics)
+
+	preferences.Toggle(TopicWaitlist)
+	assert.False(t, preferences.Allows(NotificationPromoted))
+}
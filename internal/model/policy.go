@@ -0,0 +1,142 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operation is a change of an event guarded by the permission policy.
+type Operation string
+
+const (
+	OpCreateEvent Operation = "create_event"
+	// OpAddOthers is adding chat members to an event, anyone may add themselves and guests.
+	OpAddOthers Operation = "add_others"
+	OpRemove    Operation = "remove"
+	OpMarkPaid  Operation = "mark_paid"
+	// OpEdit is renaming, moving later and changing the inviter of a participant.
+	OpEdit Operation = "edit"
+	// OpEditOthers is moving participants ahead of others and undoing changes of others.
+	OpEditOthers Operation = "edit_others"
+	// OpSchedule is setting the kickoff and the registration times, closing and reopening registration,
+	// and signing up or leaving while registration is closed.
+	OpSchedule Operation = "schedule"
+	// OpCancel is cancelling the event.
+	OpCancel Operation = "cancel"
+)
+
+var Operations = []Operation{OpCreateEvent, OpAddOthers, OpRemove, OpMarkPaid, OpEdit, OpEditOthers, OpSchedule, OpCancel}
+
+// Rule allows an operation to the users it matches.
+type Rule string
+
+const (
+	RuleAnyone Rule = "anyone"
+	// RuleSelf matches the participant the operation is applied to.
+	RuleSelf Rule = "self"
+	// RuleInviter matches the inviter of the guest the operation is applied to.
+	RuleInviter Rule = "inviter"
+	// RuleRole matches users whose role grants the operation, e.g. organizers.
+	RuleRole Rule = "role"
+	// RuleAdmin matches the Telegram administrators of the chat.
+	RuleAdmin Rule = "admin"
+)
+
+var ruleNames = []Rule{RuleAnyone, RuleSelf, RuleInviter, RuleRole, RuleAdmin}
+
+// Rules allow an operation when any of them matches.
+type Rules []Rule
+
+// Policy is the rules of each operation, an operation without rules is denied.
+type Policy map[Operation]Rules
+
+// Subject is the user asking for an operation. The role and the admin status are looked up only when a rule
+// needs them.
+type Subject struct {
+	UserId  int64
+	Role    func() (Role, error)
+	IsAdmin func() (bool, error)
+}
+
+// DefaultPolicy lets participants and inviters change their entries, owners, organizers and admins change anything,
+// treasurers mark payments.
+func DefaultPolicy() Policy {
+	return Policy{
+		OpCreateEvent: {RuleRole, RuleAdmin},
+		OpAddOthers:   {RuleRole, RuleAdmin},
+		OpRemove:      RemoveByInviter.Rules(),
+		OpMarkPaid:    {RuleSelf, RuleInviter, RuleRole, RuleAdmin},
+		OpEdit:        {RuleSelf, RuleInviter, RuleRole, RuleAdmin},
+		OpEditOthers:  {RuleRole, RuleAdmin},
+		OpSchedule:    {RuleRole, RuleAdmin},
+		OpCancel:      {RuleRole, RuleAdmin},
+	}
+}
+
+// ParseRules parses comma separated rules, e.g. self,inviter,admin.
+func ParseRules(s string) (Rules, error) {
+	var rules Rules
+	for _, name := range strings.Split(s, ",") {
+		rule := Rule(strings.ToLower(strings.TrimSpace(name)))
+		if !contains(ruleNames, rule) {
+			return nil, fmt.Errorf("unknown permission rule %q", name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Rules returns the rules removing participants under the policy, everyone can remove themselves.
+func (p RemovePolicy) Rules() Rules {
+	switch p {
+	case RemoveByAnyone:
+		return Rules{RuleAnyone}
+	case RemoveByAdmins:
+		return Rules{RuleSelf, RuleRole, RuleAdmin}
+	default:
+		return Rules{RuleSelf, RuleInviter, RuleRole, RuleAdmin}
+	}
+}
+
+// Allow checks if any of the rules allows the subject the operation on the participant, the participant is nil
+// for operations on the whole event.
+func (rules Rules) Allow(op Operation, subject Subject, participant *Participant) (bool, error) {
+	for _, rule := range rules {
+		switch rule {
+		case RuleAnyone:
+			return true, nil
+		case RuleSelf:
+			if participant != nil && participant.TelegramId != nil && *participant.TelegramId == subject.UserId {
+				return true, nil
+			}
+		case RuleInviter:
+			if participant != nil && participant.InvitedBy != nil && participant.InvitedBy.TelegramId != nil &&
+				*participant.InvitedBy.TelegramId == subject.UserId {
+				return true, nil
+			}
+		case RuleRole:
+			if subject.Role == nil {
+				continue
+			}
+			role, err := subject.Role()
+			if err != nil {
+				return false, err
+			}
+			if role.Allows(op) {
+				return true, nil
+			}
+		case RuleAdmin:
+			if subject.IsAdmin == nil {
+				continue
+			}
+			admin, err := subject.IsAdmin()
+			if err != nil {
+				return false, err
+			}
+			if admin {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
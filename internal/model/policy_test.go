@@ -0,0 +1,72 @@
+package model
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("self, Inviter,admin")
+	assert.NoError(t, err)
+	assert.Equal(t, Rules{RuleSelf, RuleInviter, RuleAdmin}, rules)
+
+	_, err = ParseRules("self,everyone")
+	assert.Error(t, err, "Unknown rule parsed")
+}
+
+func TestRules_Allow(t *testing.T) {
+	alice := &Participant{Number: 1, Name: "Alice", TelegramId: getIntPointer(111)}
+	guest := &Participant{Number: 2, Name: "Bob", InvitedBy: alice}
+	lookups := 0
+	subject := func(userId int64, role Role, admin bool) Subject {
+		return Subject{
+			UserId: userId,
+			Role: func() (Role, error) {
+				lookups++
+				return role, nil
+			},
+			IsAdmin: func() (bool, error) {
+				lookups++
+				return admin, nil
+			},
+		}
+	}
+	rules := DefaultPolicy()[OpMarkPaid]
+
+	allowed, err := rules.Allow(OpMarkPaid, subject(111, "", false), alice)
+	assert.NoError(t, err)
+	assert.True(t, allowed, "Participant can't mark themselves paid")
+	allowed, _ = rules.Allow(OpMarkPaid, subject(111, "", false), guest)
+	assert.True(t, allowed, "Inviter can't mark the guest paid")
+	assert.Equal(t, 0, lookups, "Role or admin status looked up for the participant")
+
+	allowed, _ = rules.Allow(OpMarkPaid, subject(222, RoleTreasurer, false), guest)
+	assert.True(t, allowed, "Treasurer can't mark others paid")
+	allowed, _ = rules.Allow(OpMarkPaid, subject(222, "", true), guest)
+	assert.True(t, allowed, "Admin can't mark others paid")
+	allowed, _ = rules.Allow(OpMarkPaid, subject(222, RoleMember, false), guest)
+	assert.False(t, allowed, "Member can mark others paid")
+
+	allowed, _ = Rules{RuleSelf, RuleInviter}.Allow(OpEditOthers, subject(111, "", false), nil)
+	assert.False(t, allowed, "Participant rules match an operation on the event")
+
+	failing := Subject{UserId: 222, IsAdmin: func() (bool, error) { return false, errors.New("telegram is down") }}
+	_, err = Rules{RuleAdmin}.Allow(OpRemove, failing, alice)
+	assert.Error(t, err)
+}
+
+func TestRemovePolicy_Rules(t *testing.T) {
+	alice := &Participant{Number: 1, Name: "Alice", TelegramId: getIntPointer(111)}
+	guest := &Participant{Number: 2, Name: "Bob", InvitedBy: alice}
+	member := Subject{UserId: 111}
+
+	allowed, _ := RemoveByAnyone.Rules().Allow(OpRemove, Subject{UserId: 333}, alice)
+	assert.True(t, allowed)
+	allowed, _ = RemoveByInviter.Rules().Allow(OpRemove, member, guest)
+	assert.True(t, allowed)
+	allowed, _ = RemoveByAdmins.Rules().Allow(OpRemove, member, guest)
+	assert.False(t, allowed, "Inviter can remove the guest when only admins can")
+	allowed, _ = RemoveByAdmins.Rules().Allow(OpRemove, member, alice)
+	assert.True(t, allowed, "Participant can't remove themselves")
+}
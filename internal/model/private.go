@@ -0,0 +1,109 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// startChatPrefix starts the deep-link payload selecting a group, e.g. chat-1001234567890.
+const startChatPrefix = "chat"
+
+// PrivateChat is the private chat of a user with the bot. The commands sent there change the event
+// of the selected group.
+type PrivateChat struct {
+	UserId   int64
+	ChatId   int64     `datastore:",noindex"`
+	Selected time.Time `datastore:",noindex"`
+}
+
+// GroupEvent is an event of a group the user is in.
+type GroupEvent struct {
+	ChatTitle string
+	Event     *Event
+}
+
+// SortByKickoff orders the events by the kickoff, the events without a kickoff last in the order they were created.
+func SortByKickoff(events []GroupEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i].Event, events[j].Event
+		if a.StartsAt.IsZero() != b.StartsAt.IsZero() {
+			return b.StartsAt.IsZero()
+		}
+		if a.StartsAt.IsZero() {
+			return a.Created.Before(b.Created)
+		}
+		return a.StartsAt.Before(b.StartsAt)
+	})
+}
+
+// StartPayload returns the deep-link payload selecting the group, Telegram allows letters, digits, _ and -.
+func StartPayload(chatId int64) string {
+	return fmt.Sprintf("%s%d", startChatPrefix, chatId)
+}
+
+// ParseStartPayload returns the group selected by the deep-link payload, ok is false for other payloads.
+// Private chats can't be selected, their ids are positive.
+func ParseStartPayload(payload string) (chatId int64, ok bool) {
+	id, found := strings.CutPrefix(strings.TrimSpace(payload), startChatPrefix)
+	if !found {
+		return 0, false
+	}
+	chatId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || chatId >= 0 {
+		return 0, false
+	}
+	return chatId, true
+}
+
+// IsUpcoming checks if the event is active and didn't start yet, events without a kickoff are upcoming
+// while they are active.
+func (e *Event) IsUpcoming(now time.Time) bool {
+	return e.IsActive() && (e.StartsAt.IsZero() || e.StartsAt.After(now))
+}
+
+// Due returns how much the user owes for the event, the price of each unpaid attending place taken by the user
+// or their guests. Waitlisted participants and cancelled events owe nothing.
+func (e *Event) Due(userId int64) int64 {
+	if e.Price <= 0 || e.Status == StatusCancelled {
+		return 0
+	}
+	id := strconv.FormatInt(userId, 10)
+	attending, _ := e.Lineup()
+	var due int64
+	for _, p := range attending {
+		if p.PaymentStatus.Paid {
+			continue
+		}
+		if p.Id() == id || (p.InvitedBy != nil && p.InvitedBy.Id() == id) {
+			due += e.Price
+		}
+	}
+	return due
+}
+
+// Balance sums the amounts due by currency.
+type Balance map[string]int64
+
+// Add adds the amount due for the event.
+func (b Balance) Add(event *Event, userId int64) {
+	if due := event.Due(userId); due > 0 {
+		b[event.Currency] += due
+	}
+}
+
+// String formats the amounts sorted by currency, e.g. 10.00 EUR, 5.00 USD.
+func (b Balance) String() string {
+	currencies := make([]string, 0, len(b))
+	for currency := range b {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	amounts := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		amounts = append(amounts, FormatPrice(b[currency], currency))
+	}
+	return strings.Join(amounts, ", ")
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseStartPayload(t *testing.T) {
+	chatId, ok := ParseStartPayload(StartPayload(-1001234567890))
+	assert.True(t, ok)
+	assert.Equal(t, int64(-1001234567890), chatId)
+
+	for _, payload := range []string{"", "chat", "chat42", "chatx", "-100"} {
+		_, ok := ParseStartPayload(payload)
+		assert.Falsef(t, ok, "Payload %q selects a group", payload)
+	}
+}
+
+func TestEvent_Due(t *testing.T) {
+	aliceId, bobId := int64(1), int64(2)
+	alice := &Participant{Name: "Alice", TelegramId: &aliceId}
+	event := Event{Price: 1000, Capacity: 3}
+	event.AddParticipant(alice)
+	event.AddParticipant(&Participant{Name: "Guest", InvitedBy: alice})
+	event.AddParticipant(&Participant{Name: "Bob", TelegramId: &bobId, PaymentStatus: PaymentStatus{Paid: true}})
+	event.AddParticipant(&Participant{Name: "Late guest", InvitedBy: alice})
+
+	assert.Equal(t, int64(2000), event.Due(aliceId), "The waitlisted guest owes nothing")
+	assert.Equal(t, int64(0), event.Due(bobId), "Bob has paid")
+
+	event.Cancel("rain")
+	assert.Equal(t, int64(0), event.Due(aliceId), "Cancelled events aren't paid for")
+	event.Status = StatusOpen
+
+	event.Price = 0
+	assert.Equal(t, int64(0), event.Due(aliceId))
+}
+
+func TestBalance_String(t *testing.T) {
+	userId := int64(1)
+	balance := Balance{}
+	for _, event := range []*Event{
+		{Price: 500, Currency: "USD"},
+		{Price: 1000, Currency: "EUR"},
+		{Price: 250, Currency: "EUR"},
+	} {
+		event.AddParticipant(&Participant{Name: "Alice", TelegramId: &userId})
+		balance.Add(event, userId)
+	}
+	assert.Equal(t, "12.50 EUR, 5.00 USD", balance.String())
+}
+
+func TestSortByKickoff(t *testing.T) {
+	now := time.Date(2024, time.May, 20, 12, 0, 0, 0, time.UTC)
+	events := []GroupEvent{
+		{Event: &Event{Title: "No kickoff", Created: now}},
+		{Event: &Event{Title: "Later", StartsAt: now.Add(48 * time.Hour)}},
+		{Event: &Event{Title: "Earlier no kickoff", Created: now.Add(-time.Hour)}},
+		{Event: &Event{Title: "Sooner", StartsAt: now.Add(time.Hour)}},
+	}
+	SortByKickoff(events)
+	var titles []string
+	for _, event := range events {
+		titles = append(titles, event.Event.Title)
+	}
+	assert.Equal(t, []string{"Sooner", "Later", "Earlier no kickoff", "No kickoff"}, titles)
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidTime is returned when a time can't be parsed.
+var ErrInvalidTime = errors.New("invalid time")
+
+// Time layouts accepted by ParseTime, times without a year are the next ones after now.
+const (
+	dateTimeLayout  = "02.01.2006 15:04"
+	shortTimeLayout = "02.01 15:04"
+	clockLayout     = "15:04"
+)
+
+// Announcement is a pending message about the registration of an event opening, it's deleted once sent.
+type Announcement struct {
+	ChatId  int64  `datastore:",noindex"`
+	EventId string `datastore:",noindex"`
+	Due     time.Time
+}
+
+// RegistrationOpen checks if participants may sign up and leave at the time.
+func (e *Event) RegistrationOpen(now time.Time) bool {
+	return !e.RegistrationUpcoming(now) && (e.RegistrationClosesAt.IsZero() || now.Before(e.RegistrationClosesAt))
+}
+
+// RegistrationOpenFor checks if the participant may sign up and leave at the time, participants with priority
+// may do it the early access hours before registration opens.
+func (e *Event) RegistrationOpenFor(priority bool, now time.Time) bool {
+	if e.RegistrationOpen(now) {
+		return true
+	}
+	if !priority || e.EarlyAccessHours <= 0 || !e.RegistrationUpcoming(now) {
+		return false
+	}
+	return !now.Before(e.RegistrationOpensAt.Add(-time.Duration(e.EarlyAccessHours) * time.Hour))
+}
+
+// RegistrationUpcoming checks if registration opens after the time.
+func (e *Event) RegistrationUpcoming(now time.Time) bool {
+	return !e.RegistrationOpensAt.IsZero() && now.Before(e.RegistrationOpensAt)
+}
+
+// ScheduleKickoff sets the start of the event, registration closes the hours before it unless the hours are 0.
+// Registration closed by the time stays closed, /reopen opens it.
+func (e *Event) ScheduleKickoff(startsAt time.Time, closingHours int, now time.Time) {
+	e.StartsAt = startsAt
+	if closingHours > 0 && (e.RegistrationClosesAt.IsZero() || e.RegistrationClosesAt.After(now)) {
+		e.RegistrationClosesAt = startsAt.Add(-time.Duration(closingHours) * time.Hour)
+	}
+}
+
+// ReopenRegistration lifts the registration limits, the event is open for sign up from now on.
+func (e *Event) ReopenRegistration() {
+	e.RegistrationOpensAt = time.Time{}
+	e.RegistrationClosesAt = time.Time{}
+}
+
+// ParseTime parses a time in the location like 25.05.2024 18:00, 25.05 18:00 or Sat 18:00. A time without a year
+// or with a weekday is the next such time after now.
+func ParseTime(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	now = now.In(loc)
+	if t, err := time.ParseInLocation(dateTimeLayout, value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(shortTimeLayout, value, loc); err == nil {
+		t = time.Date(now.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+		if !t.After(now) {
+			t = t.AddDate(1, 0, 0)
+		}
+		return t, nil
+	}
+	day, clock, found := strings.Cut(value, " ")
+	if !found {
+		return time.Time{}, ErrInvalidTime
+	}
+	weekday, ok := parseWeekday(day)
+	if !ok {
+		return time.Time{}, ErrInvalidTime
+	}
+	c, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return time.Time{}, ErrInvalidTime
+	}
+	days := (int(weekday) - int(now.Weekday()) + 7) % 7
+	t := time.Date(now.Year(), now.Month(), now.Day()+days, c.Hour(), c.Minute(), 0, 0, loc)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 7)
+	}
+	return t, nil
+}
+
+// FormatTime formats the time in the location the way ParseTime accepts it, empty for the zero time.
+func FormatTime(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(loc).Format(dateTimeLayout)
+}
+
+// weekdayNames are the weekday names in the languages of the bot from Sunday on, with the forms used after
+// "в" in Russian, e.g. в субботу.
+var weekdayNames = [7][]string{
+	{"sunday", "воскресенье", "sonntag"},
+	{"monday", "понедельник", "montag"},
+	{"tuesday", "вторник", "dienstag"},
+	{"wednesday", "среда", "среду", "mittwoch"},
+	{"thursday", "четверг", "donnerstag"},
+	{"friday", "пятница", "пятницу", "freitag"},
+	{"saturday", "суббота", "субботу", "samstag"},
+}
+
+// weekdayAbbreviations are the usual two letter Russian and German abbreviations from Sunday on.
+var weekdayAbbreviations = [7][]string{
+	{"вс", "so"}, {"пн", "mo"}, {"вт", "di"}, {"ср", "mi"}, {"чт", "do"}, {"пт", "fr"}, {"сб", "sa"},
+}
+
+// parseWeekday accepts English, Russian and German weekday names, their first three letters and the two letter
+// Russian and German abbreviations, e.g. Sat, saturday, Сб, суббота or Samstag.
+func parseWeekday(value string) (time.Weekday, bool) {
+	value = strings.TrimSuffix(strings.ToLower(value), ".")
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if slices.Contains(weekdayAbbreviations[d], value) {
+			return d, true
+		}
+		if utf8.RuneCountInString(value) < 3 {
+			continue
+		}
+		for _, name := range weekdayNames[d] {
+			if strings.HasPrefix(name, value) {
+				return d, true
+			}
+		}
+	}
+	return 0, false
+}
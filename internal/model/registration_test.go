@@ -0,0 +1,88 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEvent_RegistrationOpen(t *testing.T) {
+	now := time.Date(2024, time.May, 20, 12, 0, 0, 0, time.UTC)
+	event := Event{}
+	assert.True(t, event.RegistrationOpen(now), "Registration without limits is open")
+
+	event.RegistrationOpensAt = now.Add(time.Hour)
+	assert.False(t, event.RegistrationOpen(now))
+	assert.True(t, event.RegistrationUpcoming(now))
+	assert.True(t, event.RegistrationOpen(now.Add(time.Hour)))
+
+	event.ScheduleKickoff(now.Add(24*time.Hour), 6, now)
+	assert.Equal(t, now.Add(18*time.Hour), event.RegistrationClosesAt)
+	assert.True(t, event.RegistrationOpen(now.Add(17*time.Hour)))
+	assert.False(t, event.RegistrationOpen(now.Add(18*time.Hour)))
+	assert.False(t, event.RegistrationUpcoming(now.Add(18*time.Hour)))
+
+	event.ReopenRegistration()
+	assert.True(t, event.RegistrationOpen(now.Add(18*time.Hour)))
+	assert.Equal(t, now.Add(24*time.Hour), event.StartsAt, "Reopening kept the kickoff")
+}
+
+func TestEvent_ScheduleKickoffKeepsClosedRegistration(t *testing.T) {
+	now := time.Date(2024, time.May, 20, 12, 0, 0, 0, time.UTC)
+	event := Event{}
+	event.ScheduleKickoff(now.Add(48*time.Hour), 6, now)
+	assert.Equal(t, now.Add(42*time.Hour), event.RegistrationClosesAt)
+
+	event.ScheduleKickoff(now.Add(72*time.Hour), 6, now)
+	assert.Equal(t, now.Add(66*time.Hour), event.RegistrationClosesAt, "The deadline didn't follow the kickoff")
+
+	// /close
+	event.RegistrationClosesAt = now
+	event.ScheduleKickoff(now.Add(96*time.Hour), 6, now.Add(time.Minute))
+	assert.Equal(t, now, event.RegistrationClosesAt)
+	assert.False(t, event.RegistrationOpen(now.Add(time.Minute)), "Moving the kickoff reopened registration")
+}
+
+func TestEvent_RegistrationOpenFor(t *testing.T) {
+	now := time.Date(2024, time.May, 20, 12, 0, 0, 0, time.UTC)
+	event := Event{RegistrationOpensAt: now.Add(24 * time.Hour)}
+	assert.False(t, event.RegistrationOpenFor(true, now), "Priority without early access")
+
+	event.EarlyAccessHours = 24
+	assert.True(t, event.RegistrationOpenFor(true, now))
+	assert.False(t, event.RegistrationOpenFor(true, now.Add(-time.Minute)), "Early access started too early")
+	assert.False(t, event.RegistrationOpenFor(false, now))
+
+	event.RegistrationOpensAt = time.Time{}
+	event.RegistrationClosesAt = now
+	assert.False(t, event.RegistrationOpenFor(true, now), "Early access reopened closed registration")
+}
+
+func TestParseTime(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	// Monday
+	now := time.Date(2024, time.May, 20, 12, 0, 0, 0, berlin)
+
+	cases := map[string]time.Time{
+		"25.05.2024 18:00": time.Date(2024, time.May, 25, 18, 0, 0, 0, berlin),
+		"25.05 18:00":      time.Date(2024, time.May, 25, 18, 0, 0, 0, berlin),
+		"01.05  18:00":     time.Date(2025, time.May, 1, 18, 0, 0, 0, berlin),
+		"Sat 18:00":        time.Date(2024, time.May, 25, 18, 0, 0, 0, berlin),
+		"monday 11:00":     time.Date(2024, time.May, 27, 11, 0, 0, 0, berlin),
+		"Mon 13:00":        time.Date(2024, time.May, 20, 13, 0, 0, 0, berlin),
+		"Сб 18:00":         time.Date(2024, time.May, 25, 18, 0, 0, 0, berlin),
+		"субботу 18:00":    time.Date(2024, time.May, 25, 18, 0, 0, 0, berlin),
+		"Sa. 18:00":        time.Date(2024, time.May, 25, 18, 0, 0, 0, berlin),
+		"Mittwoch 18:00":   time.Date(2024, time.May, 22, 18, 0, 0, 0, berlin),
+	}
+	for value, expected := range cases {
+		parsed, err := ParseTime(value, now.In(time.UTC), berlin)
+		assert.NoError(t, err, value)
+		assert.True(t, expected.Equal(parsed), "%s parsed as %s", value, parsed)
+	}
+
+	for _, value := range []string{"", "tomorrow", "Su 18:00", "в 18:00", "Sat 25:00", "32.05 18:00"} {
+		_, err := ParseTime(value, now, berlin)
+		assert.ErrorIs(t, err, ErrInvalidTime, value)
+	}
+}
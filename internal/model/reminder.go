@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Reminder is a pending reminder of the kickoff of an event, once sent it's replaced by the next one of the
+// reminders setting.
+type Reminder struct {
+	ChatId   int64     `datastore:",noindex"`
+	EventId  string    `datastore:",noindex"`
+	StartsAt time.Time `datastore:",noindex"`
+	Due      time.Time
+}
+
+// NextReminder returns the first reminder due after the time at the hours before the kickoff, nil if the event
+// has no kickoff or all the reminders are due by the time.
+func (e *Event) NextReminder(hours []int, after time.Time) *Reminder {
+	if e.StartsAt.IsZero() {
+		return nil
+	}
+	var next *Reminder
+	for _, h := range hours {
+		due := e.StartsAt.Add(-time.Duration(h) * time.Hour)
+		if !due.After(after) || (next != nil && !due.Before(next.Due)) {
+			continue
+		}
+		next = &Reminder{ChatId: e.ChatId, EventId: e.Id(), StartsAt: e.StartsAt, Due: due}
+	}
+	return next
+}
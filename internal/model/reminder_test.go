@@ -0,0 +1,24 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEvent_NextReminder(t *testing.T) {
+	kickoff := time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC)
+	event := &Event{ChatId: -1, Created: kickoff.AddDate(0, 0, -7), StartsAt: kickoff}
+
+	next := event.NextReminder([]int{2, 24}, kickoff.AddDate(0, 0, -3))
+	assert.Equal(t, kickoff.Add(-24*time.Hour), next.Due)
+	assert.Equal(t, kickoff, next.StartsAt)
+	assert.Equal(t, event.Id(), next.EventId)
+
+	next = event.NextReminder([]int{2, 24}, next.Due)
+	assert.Equal(t, kickoff.Add(-2*time.Hour), next.Due)
+
+	assert.Nil(t, event.NextReminder([]int{2, 24}, next.Due))
+	assert.Nil(t, event.NextReminder(nil, kickoff.AddDate(0, 0, -3)))
+	assert.Nil(t, (&Event{}).NextReminder([]int{2}, kickoff))
+}
@@ -0,0 +1,17 @@
+package model
+
+import "fmt"
+
+// EventRevision keeps the participants of an event as they were before a change, the change is undone by restoring
+// them. Numbers and payments are part of the participants, so they come back as well.
+type EventRevision struct {
+	EventId string
+	// Change is the change made after the revision.
+	Change       AuditEntry     `datastore:",noindex"`
+	Participants []*Participant `datastore:",noindex"`
+	Undone       bool           `datastore:",noindex"`
+}
+
+func (r *EventRevision) Id() string {
+	return fmt.Sprintf("%s-%d", r.EventId, r.Change.Time.UnixNano())
+}
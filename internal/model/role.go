@@ -0,0 +1,134 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Role of a user in a chat, it grants permissions independent of the Telegram chat administrators.
+type Role string
+
+const (
+	RoleOwner     Role = "owner"
+	RoleOrganizer Role = "organizer"
+	RoleTreasurer Role = "treasurer"
+	RoleMember    Role = "member"
+)
+
+var roles = []Role{RoleOwner, RoleOrganizer, RoleTreasurer, RoleMember}
+
+func ParseRole(s string) (Role, bool) {
+	for _, r := range roles {
+		if strings.EqualFold(string(r), strings.TrimSpace(s)) {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// CanManageRoles allows granting and revoking roles.
+func (r Role) CanManageRoles() bool {
+	return r == RoleOwner
+}
+
+// CanManageSettings allows changing the chat settings.
+func (r Role) CanManageSettings() bool {
+	return r == RoleOwner || r == RoleOrganizer
+}
+
+// CanCreateEvents allows creating new events.
+func (r Role) CanCreateEvents() bool {
+	return r == RoleOwner || r == RoleOrganizer
+}
+
+// CanAddOthers allows adding chat members to an event.
+func (r Role) CanAddOthers() bool {
+	return r == RoleOwner || r == RoleOrganizer
+}
+
+// CanEditOthers allows renaming, moving and changing the inviter of any participant of an event.
+func (r Role) CanEditOthers() bool {
+	return r == RoleOwner || r == RoleOrganizer
+}
+
+// CanRemoveOthers allows removing any participant of an event.
+func (r Role) CanRemoveOthers() bool {
+	return r == RoleOwner || r == RoleOrganizer
+}
+
+// HasPriority gives the members of the club priority in signing up, the other roles only grant permissions.
+func (r Role) HasPriority() bool {
+	return r == RoleMember
+}
+
+// CanMarkPaid allows marking payments of any participant.
+func (r Role) CanMarkPaid() bool {
+	return r == RoleOwner || r == RoleOrganizer || r == RoleTreasurer
+}
+
+// Allows checks if the role grants the operation on any participant.
+func (r Role) Allows(op Operation) bool {
+	switch op {
+	case OpCreateEvent, OpSchedule, OpCancel:
+		return r.CanCreateEvents()
+	case OpAddOthers:
+		return r.CanAddOthers()
+	case OpRemove:
+		return r.CanRemoveOthers()
+	case OpMarkPaid:
+		return r.CanMarkPaid()
+	case OpEdit, OpEditOthers:
+		return r.CanEditOthers()
+	}
+	return false
+}
+
+// ChatUser is a Telegram user seen in a chat. Telegram doesn't resolve usernames to users for bots,
+// so users are remembered to find them by @username later.
+type ChatUser struct {
+	ChatId   int64
+	UserId   int64
+	Username string
+	Name     string
+	// ChatTitle is empty for private chats.
+	ChatTitle string    `datastore:",noindex"`
+	LastSeen  time.Time `datastore:",noindex"`
+}
+
+// Mention returns @username if the user has one, the name otherwise.
+func (u ChatUser) Mention() string {
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	return u.Name
+}
+
+// ChatRole is a role granted to a user. The user is identified by the id when it's known,
+// otherwise by the username.
+type ChatRole struct {
+	ChatId    int64
+	UserId    int64
+	Username  string
+	Name      string `datastore:",noindex"`
+	Role      Role
+	GrantedBy int64     `datastore:",noindex"`
+	Granted   time.Time `datastore:",noindex"`
+}
+
+// Matches checks if the role is granted to the user.
+func (r ChatRole) Matches(user ChatUser) bool {
+	if r.UserId != 0 && r.UserId == user.UserId {
+		return true
+	}
+	return r.Username != "" && strings.EqualFold(r.Username, user.Username)
+}
+
+// Mention returns @username if the role holder has one, the name otherwise.
+func (r ChatRole) Mention() string {
+	return ChatUser{UserId: r.UserId, Username: r.Username, Name: r.Name}.Mention()
+}
+
+// NormalizeUsername strips the leading @ and lowercases the username, Telegram usernames are case-insensitive.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseRole(t *testing.T) {
+	role, ok := ParseRole(" Organizer ")
+	assert.True(t, ok)
+	assert.Equal(t, RoleOrganizer, role)
+
+	_, ok = ParseRole("admin")
+	assert.False(t, ok, "Unknown role parsed")
+}
+
+func TestRole_Permissions(t *testing.T) {
+	assert.True(t, RoleOwner.CanManageRoles())
+	assert.False(t, RoleOrganizer.CanManageRoles())
+	assert.True(t, RoleOrganizer.CanCreateEvents())
+	assert.True(t, RoleOrganizer.CanRemoveOthers())
+	assert.True(t, RoleOrganizer.CanAddOthers())
+	assert.False(t, RoleMember.CanAddOthers())
+	assert.True(t, RoleOrganizer.CanEditOthers())
+	assert.False(t, RoleTreasurer.CanEditOthers())
+	assert.True(t, RoleTreasurer.CanMarkPaid())
+	assert.False(t, RoleTreasurer.CanCreateEvents())
+	assert.False(t, RoleMember.CanMarkPaid())
+	assert.False(t, Role("").CanCreateEvents())
+	assert.True(t, RoleMember.HasPriority())
+	assert.False(t, RoleOrganizer.HasPriority())
+	assert.False(t, RoleTreasurer.HasPriority())
+	assert.False(t, Role("").HasPriority())
+}
+
+func TestRole_Allows(t *testing.T) {
+	assert.True(t, RoleOrganizer.Allows(OpRemove))
+	assert.True(t, RoleOrganizer.Allows(OpEditOthers))
+	assert.True(t, RoleTreasurer.Allows(OpMarkPaid))
+	assert.False(t, RoleTreasurer.Allows(OpEdit))
+	assert.False(t, RoleMember.Allows(OpCreateEvent))
+}
+
+func TestChatRole_Matches(t *testing.T) {
+	byId := ChatRole{ChatId: 1, UserId: 10, Role: RoleOrganizer}
+	assert.True(t, byId.Matches(ChatUser{ChatId: 1, UserId: 10, Username: "alice"}))
+	assert.False(t, byId.Matches(ChatUser{ChatId: 1, UserId: 11}))
+
+	byUsername := ChatRole{ChatId: 1, Username: "alice", Role: RoleTreasurer}
+	assert.True(t, byUsername.Matches(ChatUser{ChatId: 1, UserId: 10, Username: "Alice"}))
+	assert.False(t, byUsername.Matches(ChatUser{ChatId: 1, UserId: 11}), "User without a username matched")
+}
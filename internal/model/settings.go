@@ -0,0 +1,320 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RemovePolicy defines who may remove other participants of an event. Chat admins, owners and organizers
+// may always remove anyone, and everyone may remove themselves.
+type RemovePolicy string
+
+const (
+	// RemoveByAnyone lets any chat member remove any participant.
+	RemoveByAnyone RemovePolicy = "anyone"
+	// RemoveByInviter lets the inviter remove the invited participants.
+	RemoveByInviter RemovePolicy = "inviter"
+	// RemoveByAdmins leaves removing others to admins, owners and organizers.
+	RemoveByAdmins RemovePolicy = "admins"
+)
+
+// ErrInvalidSetting is returned when a setting value can't be parsed, the message explains the expected value.
+var ErrInvalidSetting = errors.New("invalid setting")
+
+var RemovePolicies = []RemovePolicy{RemoveByAnyone, RemoveByInviter, RemoveByAdmins}
+
+var SupportedLanguages = []string{"en", "ru", "de"}
+
+// Settings keys accepted by ChatSettings.Set.
+const (
+	SettingTimezone   = "timezone"
+	SettingLanguage   = "language"
+	SettingCapacity   = "capacity"
+	SettingPrice      = "price"
+	SettingCurrency   = "currency"
+	SettingGuests     = "guests"
+	SettingWaitlist   = "waitlist"
+	SettingRemove     = "remove"
+	SettingReminders  = "reminders"
+	SettingClosing    = "closing"
+	SettingEarly      = "early"
+	SettingBump       = "bump"
+	SettingGuestLimit = "guestlimit"
+)
+
+var SettingKeys = []string{SettingTimezone, SettingLanguage, SettingCapacity, SettingPrice, SettingCurrency,
+	SettingGuests, SettingWaitlist, SettingRemove, SettingReminders, SettingClosing, SettingEarly, SettingBump, SettingGuestLimit}
+
+// ChatSettings configure events of a chat.
+type ChatSettings struct {
+	ChatId   int64
+	Timezone string `datastore:",noindex"`
+	Language string `datastore:",noindex"`
+	// DefaultCapacity is the capacity of new events, 0 means unlimited.
+	DefaultCapacity int `datastore:",noindex"`
+	// DefaultPrice is the price of new events in minor units of the currency, e.g. cents.
+	DefaultPrice  int64  `datastore:",noindex"`
+	Currency      string `datastore:",noindex"`
+	GuestsAllowed bool   `datastore:",noindex"`
+	// Waitlist lets people sign up after the event is full, otherwise they are rejected.
+	Waitlist     bool         `datastore:",noindex"`
+	RemoveOthers RemovePolicy `datastore:",noindex"`
+	// ReminderHours are the hours before the event start to remind the participants at.
+	ReminderHours []int `datastore:",noindex"`
+	// ClosingHours is how long before the kickoff registration closes, 0 keeps it open.
+	ClosingHours int `datastore:",noindex"`
+	// EarlyAccessHours is how long before registration opens members may sign up, 0 disables it.
+	EarlyAccessHours int `datastore:",noindex"`
+	// Bump lets members take the places of the other participants when an event is full.
+	Bump bool `datastore:",noindex"`
+	// GuestLimit is how many guests each participant of new events may invite, 0 means unlimited.
+	GuestLimit int `datastore:",noindex"`
+}
+
+func DefaultChatSettings(chatId int64) *ChatSettings {
+	return &ChatSettings{
+		ChatId:        chatId,
+		Timezone:      "UTC",
+		Language:      "en",
+		Currency:      "EUR",
+		GuestsAllowed: true,
+		Waitlist:      true,
+		RemoveOthers:  RemoveByInviter,
+	}
+}
+
+// Location returns the timezone of the chat, UTC if the timezone is unknown.
+func (s *ChatSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// NormalizeSettingKey makes the key match the setting whatever the case, e.g. Timezone is timezone.
+func NormalizeSettingKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// Set parses the value and updates the setting with the key.
+func (s *ChatSettings) Set(key string, value string) error {
+	key = NormalizeSettingKey(key)
+	value = strings.TrimSpace(value)
+	switch key {
+	case SettingTimezone:
+		if _, err := time.LoadLocation(value); err != nil || value == "" || value == "Local" {
+			return invalidSetting("unknown timezone %q, use a name like Europe/Berlin", value)
+		}
+		s.Timezone = value
+	case SettingLanguage:
+		language := strings.ToLower(value)
+		if !contains(SupportedLanguages, language) {
+			return invalidSetting("unsupported language %q, use one of %s", value, strings.Join(SupportedLanguages, ", "))
+		}
+		s.Language = language
+	case SettingCapacity:
+		capacity, err := strconv.Atoi(value)
+		if err != nil || capacity < 0 {
+			return invalidSetting("capacity must be a non-negative number, 0 means unlimited")
+		}
+		s.DefaultCapacity = capacity
+	case SettingPrice:
+		price, err := ParsePrice(value)
+		if err != nil {
+			return err
+		}
+		s.DefaultPrice = price
+	case SettingCurrency:
+		if len(value) == 0 || len(value) > 5 {
+			return invalidSetting("currency must be a code like EUR")
+		}
+		s.Currency = strings.ToUpper(value)
+	case SettingGuests:
+		allowed, err := parseSwitch(key, value)
+		if err != nil {
+			return err
+		}
+		s.GuestsAllowed = allowed
+	case SettingWaitlist:
+		enabled, err := parseSwitch(key, value)
+		if err != nil {
+			return err
+		}
+		s.Waitlist = enabled
+	case SettingRemove:
+		policy := RemovePolicy(strings.ToLower(value))
+		if !contains(RemovePolicies, policy) {
+			return invalidSetting("unknown policy %q, use anyone, inviter or admins", value)
+		}
+		s.RemoveOthers = policy
+	case SettingReminders:
+		hours, err := parseHours(value)
+		if err != nil {
+			return err
+		}
+		s.ReminderHours = hours
+	case SettingClosing:
+		hours, err := parseOptionalHours(value)
+		if err != nil {
+			return invalidSetting("closing must be hours before the kickoff like 6 or off")
+		}
+		s.ClosingHours = hours
+	case SettingEarly:
+		hours, err := parseOptionalHours(value)
+		if err != nil {
+			return invalidSetting("early must be hours before registration opens like 24 or off")
+		}
+		s.EarlyAccessHours = hours
+	case SettingBump:
+		enabled, err := parseSwitch(key, value)
+		if err != nil {
+			return err
+		}
+		s.Bump = enabled
+	case SettingGuestLimit:
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return invalidSetting("guestlimit must be a non-negative number, 0 means unlimited")
+		}
+		s.GuestLimit = limit
+	default:
+		return invalidSetting("unknown setting %q, use one of %s", key, strings.Join(SettingKeys, ", "))
+	}
+	return nil
+}
+
+// Get returns the setting with the key formatted the way Set accepts it.
+func (s *ChatSettings) Get(key string) string {
+	switch NormalizeSettingKey(key) {
+	case SettingTimezone:
+		return s.Timezone
+	case SettingLanguage:
+		return s.Language
+	case SettingCapacity:
+		return strconv.Itoa(s.DefaultCapacity)
+	case SettingPrice:
+		return FormatAmount(s.DefaultPrice)
+	case SettingCurrency:
+		return s.Currency
+	case SettingGuests:
+		return formatSwitch(s.GuestsAllowed)
+	case SettingWaitlist:
+		return formatSwitch(s.Waitlist)
+	case SettingRemove:
+		return string(s.RemoveOthers)
+	case SettingReminders:
+		return formatHours(s.ReminderHours)
+	case SettingClosing:
+		return formatOptionalHours(s.ClosingHours)
+	case SettingEarly:
+		return formatOptionalHours(s.EarlyAccessHours)
+	case SettingBump:
+		return formatSwitch(s.Bump)
+	case SettingGuestLimit:
+		return strconv.Itoa(s.GuestLimit)
+	}
+	return ""
+}
+
+// ParsePrice parses an amount like 10 or 10.50 into minor units.
+func ParsePrice(value string) (int64, error) {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+	if err != nil || amount < 0 || math.IsInf(amount, 0) {
+		return 0, invalidSetting("price must be a non-negative amount like 10 or 10.50")
+	}
+	return int64(math.Round(amount * 100)), nil
+}
+
+// FormatAmount formats minor units as an amount with two decimals.
+func FormatAmount(amount int64) string {
+	return fmt.Sprintf("%d.%02d", amount/100, amount%100)
+}
+
+// FormatPrice formats minor units with the currency, e.g. 10.50 EUR.
+func FormatPrice(amount int64, currency string) string {
+	return strings.TrimSpace(FormatAmount(amount) + " " + currency)
+}
+
+func invalidSetting(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSetting, fmt.Sprintf(format, args...))
+}
+
+func parseSwitch(key string, value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "yes", "true", "1":
+		return true, nil
+	case "off", "no", "false", "0":
+		return false, nil
+	}
+	return false, invalidSetting("%s must be on or off", key)
+}
+
+func formatSwitch(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
+
+func parseHours(value string) ([]int, error) {
+	if value == "" || strings.EqualFold(value, "off") {
+		return nil, nil
+	}
+	var hours []int
+	for _, part := range strings.Split(value, ",") {
+		h, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || h <= 0 {
+			return nil, invalidSetting("reminders must be hours before the event like 24,2 or off")
+		}
+		if !contains(hours, h) {
+			hours = append(hours, h)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(hours)))
+	return hours, nil
+}
+
+// parseOptionalHours parses a number of hours, off means 0.
+func parseOptionalHours(value string) (int, error) {
+	if strings.EqualFold(value, "off") {
+		return 0, nil
+	}
+	hours, err := strconv.Atoi(value)
+	if err != nil || hours < 0 {
+		return 0, ErrInvalidSetting
+	}
+	return hours, nil
+}
+
+func formatOptionalHours(hours int) string {
+	if hours == 0 {
+		return "off"
+	}
+	return strconv.Itoa(hours)
+}
+
+func formatHours(hours []int) string {
+	if len(hours) == 0 {
+		return "off"
+	}
+	parts := make([]string, len(hours))
+	for i, h := range hours {
+		parts[i] = strconv.Itoa(h)
+	}
+	return strings.Join(parts, ",")
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChatSettings_Set(t *testing.T) {
+	s := DefaultChatSettings(1)
+
+	assert.NoError(t, s.Set("Timezone", "Europe/Berlin"))
+	assert.NoError(t, s.Set(SettingCapacity, "14"))
+	assert.NoError(t, s.Set(SettingPrice, "7,5"))
+	assert.NoError(t, s.Set(SettingCurrency, "usd"))
+	assert.NoError(t, s.Set(SettingGuests, "off"))
+	assert.NoError(t, s.Set(SettingRemove, "admins"))
+	assert.NoError(t, s.Set(SettingReminders, "2, 24, 2"))
+	assert.NoError(t, s.Set(SettingClosing, "6"))
+	assert.NoError(t, s.Set(SettingEarly, "24"))
+	assert.NoError(t, s.Set(SettingBump, "on"))
+	assert.NoError(t, s.Set(SettingGuestLimit, "2"))
+
+	assert.Equal(t, "Europe/Berlin", s.Location().String())
+	assert.Equal(t, "Europe/Berlin", s.Get(" TimeZone"), "The key is matched whatever the case")
+	assert.Equal(t, 14, s.DefaultCapacity)
+	assert.Equal(t, int64(750), s.DefaultPrice)
+	assert.Equal(t, "7.50 USD", FormatPrice(s.DefaultPrice, s.Currency))
+	assert.False(t, s.GuestsAllowed)
+	assert.Equal(t, RemoveByAdmins, s.RemoveOthers)
+	assert.Equal(t, []int{24, 2}, s.ReminderHours)
+	assert.Equal(t, "24,2", s.Get(SettingReminders))
+	assert.Equal(t, 6, s.ClosingHours)
+	assert.Equal(t, 24, s.EarlyAccessHours)
+	assert.True(t, s.Bump)
+	assert.Equal(t, 2, s.GuestLimit)
+	assert.NoError(t, s.Set(SettingClosing, "off"))
+	assert.Equal(t, "off", s.Get(SettingClosing))
+}
+
+func TestChatSettings_SetRejectsInvalidValues(t *testing.T) {
+	s := DefaultChatSettings(1)
+
+	assert.ErrorIs(t, s.Set(SettingTimezone, "Mars/Olympus"), ErrInvalidSetting)
+	assert.ErrorIs(t, s.Set(SettingLanguage, "fr"), ErrInvalidSetting)
+	assert.ErrorIs(t, s.Set(SettingCapacity, "-1"), ErrInvalidSetting)
+	assert.ErrorIs(t, s.Set(SettingPrice, "free"), ErrInvalidSetting)
+	assert.ErrorIs(t, s.Set(SettingWaitlist, "maybe"), ErrInvalidSetting)
+	assert.ErrorIs(t, s.Set(SettingClosing, "soon"), ErrInvalidSetting)
+	assert.ErrorIs(t, s.Set(SettingEarly, "-1"), ErrInvalidSetting)
+	assert.ErrorIs(t, s.Set(SettingGuestLimit, "many"), ErrInvalidSetting)
+	assert.ErrorIs(t, s.Set("color", "red"), ErrInvalidSetting)
+	assert.Equal(t, DefaultChatSettings(1), s, "Invalid values changed the settings")
+}
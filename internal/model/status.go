@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// Status is the stage of the life of an event.
+type Status string
+
+const (
+	// StatusDraft is an event whose registration opens later, see /opens.
+	StatusDraft Status = "draft"
+	StatusOpen  Status = "open"
+	// StatusClosed is an event whose registration was closed with /close or by the deadline, or which was
+	// replaced by a newer one before its kickoff.
+	StatusClosed    Status = "closed"
+	StatusCancelled Status = "cancelled"
+	// StatusCompleted is an event which started.
+	StatusCompleted Status = "completed"
+)
+
+// IsActive checks if the event didn't end, it's neither cancelled nor completed.
+func (e *Event) IsActive() bool {
+	return e.Status != StatusCancelled && e.Status != StatusCompleted
+}
+
+// Refresh moves the status along with the time: the event is a draft until registration opens, open until it
+// closes, closed until the kickoff and completed afterwards. Cancelled and completed events are kept as they are.
+func (e *Event) Refresh(now time.Time) {
+	switch {
+	case !e.IsActive():
+	case !e.StartsAt.IsZero() && !now.Before(e.StartsAt):
+		e.Status = StatusCompleted
+	case e.RegistrationUpcoming(now):
+		e.Status = StatusDraft
+	case e.RegistrationOpen(now):
+		e.Status = StatusOpen
+	default:
+		e.Status = StatusClosed
+	}
+}
+
+// Finish ends the event replaced by a new one, it's completed if it already started.
+func (e *Event) Finish(now time.Time) {
+	if !e.StartsAt.IsZero() && !e.StartsAt.After(now) {
+		e.Status = StatusCompleted
+	} else {
+		e.Status = StatusClosed
+	}
+}
+
+// Cancel calls the event off, the reason may be empty.
+func (e *Event) Cancel(reason string) {
+	e.Status = StatusCancelled
+	e.CancelReason = reason
+}
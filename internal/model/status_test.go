@@ -0,0 +1,67 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEvent_FinishBeforeAndAfterKickoff(t *testing.T) {
+	now := time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC)
+
+	event := &Event{Status: StatusOpen}
+	event.Finish(now)
+	assert.Equal(t, StatusClosed, event.Status)
+
+	event = &Event{Status: StatusOpen, StartsAt: now.Add(time.Hour)}
+	event.Finish(now)
+	assert.Equal(t, StatusClosed, event.Status)
+
+	event = &Event{Status: StatusOpen, StartsAt: now.Add(-time.Hour)}
+	event.Finish(now)
+	assert.Equal(t, StatusCompleted, event.Status)
+}
+
+func TestEvent_Refresh(t *testing.T) {
+	now := time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC)
+	event := &Event{Status: StatusOpen, RegistrationOpensAt: now.Add(time.Hour)}
+	event.ScheduleKickoff(now.Add(4*time.Hour), 1, now)
+
+	event.Refresh(now)
+	assert.Equal(t, StatusDraft, event.Status)
+	assert.True(t, event.IsActive())
+
+	event.Refresh(now.Add(time.Hour))
+	assert.Equal(t, StatusOpen, event.Status)
+
+	event.Refresh(now.Add(3 * time.Hour))
+	assert.Equal(t, StatusClosed, event.Status)
+	assert.True(t, event.IsActive())
+
+	event.Refresh(now.Add(4 * time.Hour))
+	assert.Equal(t, StatusCompleted, event.Status)
+	assert.False(t, event.IsActive())
+
+	event.Refresh(now)
+	assert.Equal(t, StatusCompleted, event.Status)
+}
+
+func TestEvent_RefreshKeepsCancelled(t *testing.T) {
+	now := time.Date(2024, time.May, 4, 18, 0, 0, 0, time.UTC)
+	event := &Event{Status: StatusOpen, StartsAt: now.Add(-time.Hour)}
+	event.Cancel("rain")
+
+	event.Refresh(now)
+	assert.Equal(t, StatusCancelled, event.Status)
+}
+
+func TestEvent_Cancel(t *testing.T) {
+	event := &Event{Status: StatusOpen}
+	assert.True(t, event.IsActive())
+
+	event.Cancel("rain")
+
+	assert.Equal(t, StatusCancelled, event.Status)
+	assert.Equal(t, "rain", event.CancelReason)
+	assert.False(t, event.IsActive())
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// ChatTemplate is a custom template rendering the events of a chat instead of the default one.
+type ChatTemplate struct {
+	ChatId    int64
+	Source    string    `datastore:",noindex"`
+	UpdatedBy int64     `datastore:",noindex"`
+	Updated   time.Time `datastore:",noindex"`
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"errors"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// GetDueAnnouncements returns the announcements due by the time.
+func (r *EventRepository) GetDueAnnouncements(ctx context.Context, now time.Time) (_ []*model.Announcement, err error) {
+	defer metrics.ObserveRepositoryOp("get_due_announcements", time.Now(), &err)
+	query := datastore.NewQuery("Announcement").FilterField("Due", "<=", now)
+	var announcements []*model.Announcement
+	if _, err = r.dsClient.GetAll(ctx, query, &announcements); err != nil {
+		log.Error().Msgf("Failed to get the due announcements: %s.", err)
+		return nil, err
+	}
+	return announcements, nil
+}
+
+// GetAnnouncement returns the announcement of the event, nil if there is none.
+func (r *EventRepository) GetAnnouncement(ctx context.Context, eventId string) (_ *model.Announcement, err error) {
+	defer metrics.ObserveRepositoryOp("get_announcement", time.Now(), &err)
+	var announcement model.Announcement
+	err = r.get(ctx, datastore.NameKey("Announcement", eventId, nil), &announcement)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to get the announcement of the event %s: %s.", eventId, err)
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+// SaveAnnouncement replaces the announcement of the event.
+func (r *EventRepository) SaveAnnouncement(ctx context.Context, announcement *model.Announcement) (err error) {
+	defer metrics.ObserveRepositoryOp("save_announcement", time.Now(), &err)
+	err = r.put(ctx, datastore.NameKey("Announcement", announcement.EventId, nil), announcement)
+	if err != nil {
+		log.Error().Msgf("Failed to save the announcement of the event %s: %s.", announcement.EventId, err)
+	}
+	return err
+}
+
+// DeleteAnnouncement deletes the announcement of the event, it's not an error if there is none.
+func (r *EventRepository) DeleteAnnouncement(ctx context.Context, eventId string) (err error) {
+	defer metrics.ObserveRepositoryOp("delete_announcement", time.Now(), &err)
+	err = r.deleteMulti(ctx, []*datastore.Key{datastore.NameKey("Announcement", eventId, nil)})
+	if err != nil {
+		log.Error().Msgf("Failed to delete the announcement of the event %s: %s.", eventId, err)
+	}
+	return err
+}
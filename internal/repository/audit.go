@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"github.com/rs/zerolog/log"
+	"sort"
+	"time"
+)
+
+// GetAuditEntries returns the latest entries of the event and the changes of the chat made since the time,
+// the newest first. Events have a limited number of changes, so they are filtered and sorted here instead of
+// requiring composite indexes.
+func (r *EventRepository) GetAuditEntries(ctx context.Context, chatId int64, eventId string, since time.Time, limit int) (_ []*model.AuditEntry, err error) {
+	defer metrics.ObserveRepositoryOp("get_audit_entries", time.Now(), &err)
+	var entries []*model.AuditEntry
+	query := datastore.NewQuery("AuditEntry").FilterField("EventId", "=", eventId)
+	if _, err = r.dsClient.GetAll(ctx, query, &entries); err != nil {
+		log.Error().Msgf("Failed to get the audit entries of the event %s: %s.", eventId, err)
+		return nil, err
+	}
+	var chatEntries []*model.AuditEntry
+	query = datastore.NewQuery("AuditEntry").FilterField("ChatId", "=", chatId).FilterField("EventId", "=", "")
+	if _, err = r.dsClient.GetAll(ctx, query, &chatEntries); err != nil {
+		log.Error().Msgf("Failed to get the audit entries of the chat %d: %s.", chatId, err)
+		return nil, err
+	}
+	for _, entry := range chatEntries {
+		if !entry.Time.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// SaveAuditEntry appends the entry to the audit log, entries are never changed.
+func (r *EventRepository) SaveAuditEntry(ctx context.Context, entry *model.AuditEntry) (err error) {
+	defer metrics.ObserveRepositoryOp("save_audit_entry", time.Now(), &err)
+	err = r.put(ctx, datastore.IncompleteKey("AuditEntry", nil), entry)
+	if err != nil {
+		log.Error().Msgf("Failed to save the audit entry %s of the event %s: %s.", entry.Action, entry.EventId, err)
+	}
+	return err
+}
+
+// SaveChange saves the changed event with the revision to undo the change, nil if it can't be undone, and
+// the audit entry of the change in the transaction the event was read in, so a change is never stored without
+// its record nor over a concurrent one.
+func (r *EventRepository) SaveChange(ctx context.Context, event *model.Event, revision *model.EventRevision, entry *model.AuditEntry) (err error) {
+	defer metrics.ObserveRepositoryOp("save_change", time.Now(), &err)
+	err = r.RunInTransaction(ctx, false, func(ctx context.Context) error {
+		if err := r.put(ctx, datastore.NameKey("Event", event.Id(), nil), event); err != nil {
+			return err
+		}
+		if revision != nil {
+			if err := r.put(ctx, datastore.NameKey("EventRevision", revision.Id(), nil), revision); err != nil {
+				return err
+			}
+		}
+		return r.put(ctx, datastore.IncompleteKey("AuditEntry", nil), entry)
+	})
+	if err != nil {
+		log.Error().Msgf("Failed to save the change %s of the event %s: %s.", entry.Action, event.Id(), err)
+	}
+	return err
+}
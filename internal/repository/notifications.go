@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+func notificationPreferencesKey(userId int64) *datastore.Key {
+	return datastore.IDKey("NotificationPreferences", userId, nil)
+}
+
+// GetNotificationPreferences returns the preferences of the user, nil if the user never changed them.
+func (r *EventRepository) GetNotificationPreferences(ctx context.Context, userId int64) (_ *model.NotificationPreferences, err error) {
+	defer metrics.ObserveRepositoryOp("get_notification_preferences", time.Now(), &err)
+	var preferences model.NotificationPreferences
+	err = r.dsClient.Get(ctx, notificationPreferencesKey(userId), &preferences)
+	if err == datastore.ErrNoSuchEntity {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to get the notification preferences of the user %d: %s.", userId, err)
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+func (r *EventRepository) SaveNotificationPreferences(ctx context.Context, preferences *model.NotificationPreferences) (err error) {
+	defer metrics.ObserveRepositoryOp("save_notification_preferences", time.Now(), &err)
+	if _, err = r.dsClient.Put(ctx, notificationPreferencesKey(preferences.UserId), preferences); err != nil {
+		log.Error().Msgf("Failed to save the notification preferences of the user %d: %s.", preferences.UserId, err)
+	}
+	return err
+}
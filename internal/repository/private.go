@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+func privateChatKey(userId int64) *datastore.Key {
+	return datastore.IDKey("PrivateChat", userId, nil)
+}
+
+// GetPrivateChat returns the private chat of the user, nil if the user didn't select a group yet.
+func (r *EventRepository) GetPrivateChat(ctx context.Context, userId int64) (_ *model.PrivateChat, err error) {
+	defer metrics.ObserveRepositoryOp("get_private_chat", time.Now(), &err)
+	var chat model.PrivateChat
+	err = r.dsClient.Get(ctx, privateChatKey(userId), &chat)
+	if err == datastore.ErrNoSuchEntity {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to get the private chat of the user %d: %s.", userId, err)
+		return nil, err
+	}
+	return &chat, nil
+}
+
+func (r *EventRepository) SavePrivateChat(ctx context.Context, chat *model.PrivateChat) (err error) {
+	defer metrics.ObserveRepositoryOp("save_private_chat", time.Now(), &err)
+	if _, err = r.dsClient.Put(ctx, privateChatKey(chat.UserId), chat); err != nil {
+		log.Error().Msgf("Failed to save the private chat of the user %d: %s.", chat.UserId, err)
+	}
+	return err
+}
+
+// GetChatsOfUser returns the records of the user in every chat the user was seen in.
+func (r *EventRepository) GetChatsOfUser(ctx context.Context, userId int64) (_ []*model.ChatUser, err error) {
+	defer metrics.ObserveRepositoryOp("get_chats_of_user", time.Now(), &err)
+	query := datastore.NewQuery("ChatUser").FilterField("UserId", "=", userId)
+	var users []*model.ChatUser
+	if _, err = r.dsClient.GetAll(ctx, query, &users); err != nil {
+		log.Error().Msgf("Failed to get the chats of the user %d: %s.", userId, err)
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"errors"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// GetDueReminders returns the reminders due by the time.
+func (r *EventRepository) GetDueReminders(ctx context.Context, now time.Time) (_ []*model.Reminder, err error) {
+	defer metrics.ObserveRepositoryOp("get_due_reminders", time.Now(), &err)
+	query := datastore.NewQuery("Reminder").FilterField("Due", "<=", now)
+	var reminders []*model.Reminder
+	if _, err = r.dsClient.GetAll(ctx, query, &reminders); err != nil {
+		log.Error().Msgf("Failed to get the due reminders: %s.", err)
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// GetReminder returns the reminder of the event, nil if there is none.
+func (r *EventRepository) GetReminder(ctx context.Context, eventId string) (_ *model.Reminder, err error) {
+	defer metrics.ObserveRepositoryOp("get_reminder", time.Now(), &err)
+	var reminder model.Reminder
+	err = r.get(ctx, datastore.NameKey("Reminder", eventId, nil), &reminder)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to get the reminder of the event %s: %s.", eventId, err)
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// SaveReminder replaces the reminder of the event.
+func (r *EventRepository) SaveReminder(ctx context.Context, reminder *model.Reminder) (err error) {
+	defer metrics.ObserveRepositoryOp("save_reminder", time.Now(), &err)
+	err = r.put(ctx, datastore.NameKey("Reminder", reminder.EventId, nil), reminder)
+	if err != nil {
+		log.Error().Msgf("Failed to save the reminder of the event %s: %s.", reminder.EventId, err)
+	}
+	return err
+}
+
+// DeleteReminder deletes the reminder of the event, it's not an error if there is none.
+func (r *EventRepository) DeleteReminder(ctx context.Context, eventId string) (err error) {
+	defer metrics.ObserveRepositoryOp("delete_reminder", time.Now(), &err)
+	err = r.deleteMulti(ctx, []*datastore.Key{datastore.NameKey("Reminder", eventId, nil)})
+	if err != nil {
+		log.Error().Msgf("Failed to delete the reminder of the event %s: %s.", eventId, err)
+	}
+	return err
+}
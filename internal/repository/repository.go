@@ -3,13 +3,23 @@ package repository
 import (
 	"cloud.google.com/go/datastore"
 	"context"
+	"event-gorganizer/internal/metrics"
 	"event-gorganizer/internal/model"
-	"fmt"
+	"event-gorganizer/internal/tracing"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
+var tracer = otel.Tracer("event-gorganizer/internal/repository")
+
 type EventRepository struct {
 	dsClient *datastore.Client
 }
@@ -35,9 +45,25 @@ func NewEventRepository(ctx context.Context, settings GcpSettings) (*EventReposi
 	}, nil
 }
 
-func (r *EventRepository) Save(ctx context.Context, event *model.Event) (*model.Event, error) {
+func (r *EventRepository) Close() error {
+	return r.dsClient.Close()
+}
+
+// Ping checks that the storage is reachable.
+func (r *EventRepository) Ping(ctx context.Context) (err error) {
+	defer metrics.ObserveRepositoryOp("ping", time.Now(), &err)
+	query := datastore.NewQuery("Event").KeysOnly().Limit(1)
+	_, err = r.dsClient.Run(ctx, query).Next(nil)
+	if err != nil && err != iterator.Done {
+		return err
+	}
+	return nil
+}
+
+func (r *EventRepository) Save(ctx context.Context, event *model.Event) (_ *model.Event, err error) {
+	defer metrics.ObserveRepositoryOp("save_event", time.Now(), &err)
 	key := datastore.NameKey("Event", event.Id(), nil)
-	_, err := r.dsClient.Put(ctx, key, event)
+	err = r.put(ctx, key, event)
 	if err != nil {
 		log.Error().Msgf("Failed to save the event %s: %s", event.Id(), err)
 		return nil, err
@@ -45,56 +71,167 @@ func (r *EventRepository) Save(ctx context.Context, event *model.Event) (*model.
 	return event, nil
 }
 
-func (r *EventRepository) GetActiveEvent(ctx context.Context, chatId int64) (*model.Event, error) {
-	query := datastore.NewQuery("Event").
-		FilterField("ChatId", "=", chatId).
-		FilterField("Active", "=", true).
-		Limit(1)
+// GetActiveEvent returns the current event of the chat: the latest one unless it was cancelled, nil if there is none.
+func (r *EventRepository) GetActiveEvent(ctx context.Context, chatId int64) (_ *model.Event, err error) {
+	defer metrics.ObserveRepositoryOp("get_active_event", time.Now(), &err)
+	events, err := r.GetEvents(ctx, chatId, 1)
+	if err != nil || len(events) == 0 || events[0].Status == model.StatusCancelled {
+		return nil, err
+	}
+	return events[0], nil
+}
 
-	iter := r.dsClient.Run(ctx, query)
-	var event model.Event
-	_, err := iter.Next(&event)
-	if err != nil && err != iterator.Done {
-		log.Error().Msgf("Failed to get an event for the chat %s: %s.", chatId, err)
+// GetEvents returns the latest events of the chat, the newest first. The keys contain the creation time,
+// so they are sorted here instead of requiring a composite index and only the returned events are loaded.
+// The keys are queried outside the transaction of the context, the events are read in it.
+func (r *EventRepository) GetEvents(ctx context.Context, chatId int64, limit int) (_ []*model.Event, err error) {
+	defer metrics.ObserveRepositoryOp("get_events", time.Now(), &err)
+	query := datastore.NewQuery("Event").FilterField("ChatId", "=", chatId).KeysOnly()
+	keys, err := r.dsClient.GetAll(ctx, query, nil)
+	if err != nil {
+		log.Error().Msgf("Failed to get the events of the chat %d: %s.", chatId, err)
+		return nil, err
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return createdOf(keys[i]) > createdOf(keys[j])
+	})
+	keys = keys[:min(limit, len(keys))]
+	stored := make([]*storedEvent, len(keys))
+	for i := range stored {
+		stored[i] = &storedEvent{}
+	}
+	if err = r.getMulti(ctx, keys, stored); err != nil {
+		log.Error().Msgf("Failed to get the events of the chat %d: %s.", chatId, err)
 		return nil, err
 	}
-	return &event, nil
+	events := make([]*model.Event, len(stored))
+	for i, event := range stored {
+		events[i] = (*model.Event)(event)
+	}
+	return events, nil
+}
+
+// storedEvent reads the events stored before statuses were introduced, they are saved with the status.
+type storedEvent model.Event
+
+// Load sets the status of the events stored with Active instead, active ones are open and the others closed.
+func (e *storedEvent) Load(props []datastore.Property) error {
+	var active, legacy bool
+	kept := make([]datastore.Property, 0, len(props))
+	for _, prop := range props {
+		if prop.Name == "Active" {
+			active, _ = prop.Value.(bool)
+			legacy = true
+			continue
+		}
+		kept = append(kept, prop)
+	}
+	if err := datastore.LoadStruct(e, kept); err != nil {
+		return err
+	}
+	if e.Status == "" && legacy {
+		e.Status = model.StatusClosed
+		if active {
+			e.Status = model.StatusOpen
+		}
+	}
+	return nil
+}
+
+func (e *storedEvent) Save() ([]datastore.Property, error) {
+	return datastore.SaveStruct(e)
 }
 
-func ExecTx[R any](ctx context.Context, repo *EventRepository, readonly bool, f func() (*R, error)) (*R, error) {
+// createdOf returns the creation time of the event in Unix seconds from its key, see model.Event.Id.
+func createdOf(key *datastore.Key) int64 {
+	created, _ := strconv.ParseInt(key.Name[strings.LastIndex(key.Name, "-")+1:], 10, 64)
+	return created
+}
+
+type txCtxKey struct{}
+
+// transaction returns the transaction the context is run in, nil outside of ExecTx.
+func transaction(ctx context.Context) *datastore.Transaction {
+	tx, _ := ctx.Value(txCtxKey{}).(*datastore.Transaction)
+	return tx
+}
+
+// RunInTransaction runs f with a context the repository reads and writes the entities in the transaction with,
+// the changes are committed when f succeeds. The transaction is retried when the entities read were changed
+// concurrently, f is called again then. Transactions are joined, f is called with the context as is inside one.
+func (r *EventRepository) RunInTransaction(ctx context.Context, readonly bool, f func(context.Context) error) error {
+	if transaction(ctx) != nil {
+		return f(ctx)
+	}
 	var opts []datastore.TransactionOption
 	if readonly {
 		opts = []datastore.TransactionOption{datastore.ReadOnly}
 	}
+	_, err := r.dsClient.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		// the transaction is rolled back by the client, the error is returned as is
+		return f(context.WithValue(ctx, txCtxKey{}, tx))
+	}, opts...)
+	return err
+}
+
+// Transactor runs functions in transactions, see EventRepository.RunInTransaction.
+type Transactor interface {
+	RunInTransaction(ctx context.Context, readonly bool, f func(context.Context) error) error
+}
+
+func ExecTx[R any](ctx context.Context, repo Transactor, readonly bool, f func(context.Context) (*R, error)) (_ *R, err error) {
+	defer metrics.ObserveRepositoryOp("transaction", time.Now(), &err)
+	ctx, span := tracer.Start(ctx, "repository.ExecTx", trace.WithAttributes(attribute.Bool("tx.readonly", readonly)))
+	defer tracing.End(span, &err)
 	var r *R
-	_, err := repo.dsClient.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
-		result, e := f()
+	err = repo.RunInTransaction(ctx, readonly, func(ctx context.Context) error {
+		result, e := f(ctx)
 		if e != nil {
-			log.Error().Err(e)
-			if rbErr := tx.Rollback(); rbErr != nil {
-				return fmt.Errorf("tx err: %v, rb err: %v", e, rbErr)
-			}
+			return e
 		}
 		r = result
 		return nil
-	}, opts...)
+	})
 	return r, err
 }
 
-func ExecVoidTx(ctx context.Context, repo *EventRepository, readonly bool, f func() error) error {
-	var opts []datastore.TransactionOption
-	if readonly {
-		opts = []datastore.TransactionOption{datastore.ReadOnly}
+func ExecVoidTx(ctx context.Context, repo Transactor, readonly bool, f func(context.Context) error) (err error) {
+	defer metrics.ObserveRepositoryOp("transaction", time.Now(), &err)
+	ctx, span := tracer.Start(ctx, "repository.ExecVoidTx", trace.WithAttributes(attribute.Bool("tx.readonly", readonly)))
+	defer tracing.End(span, &err)
+	return repo.RunInTransaction(ctx, readonly, f)
+}
+
+// get reads the entity in the transaction of the context if there is one.
+func (r *EventRepository) get(ctx context.Context, key *datastore.Key, dst any) error {
+	if tx := transaction(ctx); tx != nil {
+		return tx.Get(key, dst)
 	}
-	_, err := repo.dsClient.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
-		e := f()
-		if e != nil {
-			log.Error().Err(e)
-			if rbErr := tx.Rollback(); rbErr != nil {
-				return fmt.Errorf("tx err: %v, rb err: %v", e, rbErr)
-			}
-		}
-		return nil
-	}, opts...)
+	return r.dsClient.Get(ctx, key, dst)
+}
+
+// getMulti reads the entities in the transaction of the context if there is one.
+func (r *EventRepository) getMulti(ctx context.Context, keys []*datastore.Key, dst any) error {
+	if tx := transaction(ctx); tx != nil {
+		return tx.GetMulti(keys, dst)
+	}
+	return r.dsClient.GetMulti(ctx, keys, dst)
+}
+
+// put writes the entity in the transaction of the context if there is one, it's stored on commit then.
+func (r *EventRepository) put(ctx context.Context, key *datastore.Key, src any) error {
+	if tx := transaction(ctx); tx != nil {
+		_, err := tx.Put(key, src)
+		return err
+	}
+	_, err := r.dsClient.Put(ctx, key, src)
 	return err
 }
+
+// deleteMulti deletes the entities in the transaction of the context if there is one.
+func (r *EventRepository) deleteMulti(ctx context.Context, keys []*datastore.Key) error {
+	if tx := transaction(ctx); tx != nil {
+		return tx.DeleteMulti(keys)
+	}
+	return r.dsClient.DeleteMulti(ctx, keys)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStoredEvent_LoadLegacyActive(t *testing.T) {
+	var active storedEvent
+	err := active.Load([]datastore.Property{{Name: "Title", Value: "Football"}, {Name: "Active", Value: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Football", active.Title)
+	assert.Equal(t, model.StatusOpen, active.Status)
+
+	var closed storedEvent
+	assert.NoError(t, closed.Load([]datastore.Property{{Name: "Active", Value: false}}))
+	assert.Equal(t, model.StatusClosed, closed.Status)
+
+	var cancelled storedEvent
+	assert.NoError(t, cancelled.Load([]datastore.Property{{Name: "Status", Value: "cancelled"}}))
+	assert.Equal(t, model.StatusCancelled, cancelled.Status)
+}
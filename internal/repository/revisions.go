@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"github.com/rs/zerolog/log"
+	"sort"
+	"time"
+)
+
+// GetRevisions returns the revisions of the event, the newest first.
+func (r *EventRepository) GetRevisions(ctx context.Context, eventId string) (_ []*model.EventRevision, err error) {
+	defer metrics.ObserveRepositoryOp("get_revisions", time.Now(), &err)
+	query := datastore.NewQuery("EventRevision").FilterField("EventId", "=", eventId)
+	var revisions []*model.EventRevision
+	if _, err = r.dsClient.GetAll(ctx, query, &revisions); err != nil {
+		log.Error().Msgf("Failed to get the revisions of the event %s: %s.", eventId, err)
+		return nil, err
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].Change.Time.After(revisions[j].Change.Time)
+	})
+	return revisions, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+func chatUserKey(chatId int64, userId int64) *datastore.Key {
+	return datastore.NameKey("ChatUser", fmt.Sprintf("%d-%d", chatId, userId), nil)
+}
+
+// roleKeys returns the keys the role of the user can be stored with, by the id and by the username.
+func roleKeys(chatId int64, userId int64, username string) []*datastore.Key {
+	var keys []*datastore.Key
+	if userId != 0 {
+		keys = append(keys, datastore.NameKey("ChatRole", fmt.Sprintf("%d-%d", chatId, userId), nil))
+	}
+	if username != "" {
+		keys = append(keys, datastore.NameKey("ChatRole", fmt.Sprintf("%d-@%s", chatId, username), nil))
+	}
+	return keys
+}
+
+func (r *EventRepository) SaveChatUser(ctx context.Context, user *model.ChatUser) (err error) {
+	defer metrics.ObserveRepositoryOp("save_chat_user", time.Now(), &err)
+	_, err = r.dsClient.Put(ctx, chatUserKey(user.ChatId, user.UserId), user)
+	if err != nil {
+		log.Error().Msgf("Failed to save the user %d of the chat %d: %s.", user.UserId, user.ChatId, err)
+	}
+	return err
+}
+
+// FindChatUserByUsername returns the user of the chat with the username, nil if the user wasn't seen in the chat.
+func (r *EventRepository) FindChatUserByUsername(ctx context.Context, chatId int64, username string) (_ *model.ChatUser, err error) {
+	defer metrics.ObserveRepositoryOp("find_chat_user", time.Now(), &err)
+	query := datastore.NewQuery("ChatUser").
+		FilterField("ChatId", "=", chatId).
+		FilterField("Username", "=", username).
+		Limit(1)
+	var users []*model.ChatUser
+	if _, err = r.dsClient.GetAll(ctx, query, &users); err != nil {
+		log.Error().Msgf("Failed to find the user %s of the chat %d: %s.", username, chatId, err)
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
+// SaveRole stores the role replacing any other role of the user in the chat.
+func (r *EventRepository) SaveRole(ctx context.Context, role *model.ChatRole) (err error) {
+	defer metrics.ObserveRepositoryOp("save_role", time.Now(), &err)
+	keys := roleKeys(role.ChatId, role.UserId, role.Username)
+	if err = r.deleteMulti(ctx, keys[1:]); err != nil {
+		log.Error().Msgf("Failed to delete previous roles of %s in the chat %d: %s.", role.Mention(), role.ChatId, err)
+		return err
+	}
+	if err = r.put(ctx, keys[0], role); err != nil {
+		log.Error().Msgf("Failed to save the role of %s in the chat %d: %s.", role.Mention(), role.ChatId, err)
+	}
+	return err
+}
+
+// DeleteRole removes the role of the user in the chat.
+func (r *EventRepository) DeleteRole(ctx context.Context, chatId int64, user model.ChatUser) (err error) {
+	defer metrics.ObserveRepositoryOp("delete_role", time.Now(), &err)
+	if err = r.deleteMulti(ctx, roleKeys(chatId, user.UserId, user.Username)); err != nil {
+		log.Error().Msgf("Failed to delete the role of %s in the chat %d: %s.", user.Mention(), chatId, err)
+	}
+	return err
+}
+
+func (r *EventRepository) GetRoles(ctx context.Context, chatId int64) (_ []*model.ChatRole, err error) {
+	defer metrics.ObserveRepositoryOp("get_roles", time.Now(), &err)
+	query := datastore.NewQuery("ChatRole").FilterField("ChatId", "=", chatId)
+	var roles []*model.ChatRole
+	if _, err = r.dsClient.GetAll(ctx, query, &roles); err != nil {
+		log.Error().Msgf("Failed to get roles of the chat %d: %s.", chatId, err)
+		return nil, err
+	}
+	return roles, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"errors"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"time"
+)
+
+func chatSettingsKey(chatId int64) *datastore.Key {
+	return datastore.NameKey("ChatSettings", strconv.FormatInt(chatId, 10), nil)
+}
+
+// GetSettings returns the settings of the chat, nil if they were never changed.
+func (r *EventRepository) GetSettings(ctx context.Context, chatId int64) (_ *model.ChatSettings, err error) {
+	defer metrics.ObserveRepositoryOp("get_settings", time.Now(), &err)
+	var settings model.ChatSettings
+	err = r.get(ctx, chatSettingsKey(chatId), &settings)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to get settings of the chat %d: %s.", chatId, err)
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *EventRepository) SaveSettings(ctx context.Context, settings *model.ChatSettings) (err error) {
+	defer metrics.ObserveRepositoryOp("save_settings", time.Now(), &err)
+	err = r.put(ctx, chatSettingsKey(settings.ChatId), settings)
+	if err != nil {
+		log.Error().Msgf("Failed to save settings of the chat %d: %s.", settings.ChatId, err)
+	}
+	return err
+}
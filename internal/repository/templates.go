@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"errors"
+	"event-gorganizer/internal/metrics"
+	"event-gorganizer/internal/model"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"time"
+)
+
+func chatTemplateKey(chatId int64) *datastore.Key {
+	return datastore.NameKey("ChatTemplate", strconv.FormatInt(chatId, 10), nil)
+}
+
+// GetTemplate returns the custom template of the chat, nil if the chat uses the default one.
+func (r *EventRepository) GetTemplate(ctx context.Context, chatId int64) (_ *model.ChatTemplate, err error) {
+	defer metrics.ObserveRepositoryOp("get_template", time.Now(), &err)
+	var template model.ChatTemplate
+	err = r.get(ctx, chatTemplateKey(chatId), &template)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to get the template of the chat %d: %s.", chatId, err)
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *EventRepository) SaveTemplate(ctx context.Context, template *model.ChatTemplate) (err error) {
+	defer metrics.ObserveRepositoryOp("save_template", time.Now(), &err)
+	err = r.put(ctx, chatTemplateKey(template.ChatId), template)
+	if err != nil {
+		log.Error().Msgf("Failed to save the template of the chat %d: %s.", template.ChatId, err)
+	}
+	return err
+}
+
+func (r *EventRepository) DeleteTemplate(ctx context.Context, chatId int64) (err error) {
+	defer metrics.ObserveRepositoryOp("delete_template", time.Now(), &err)
+	err = r.deleteMulti(ctx, []*datastore.Key{chatTemplateKey(chatId)})
+	if err != nil {
+		log.Error().Msgf("Failed to delete the template of the chat %d: %s.", chatId, err)
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"cloud.google.com/go/datastore"
+	"context"
+	"errors"
+	"event-gorganizer/internal/metrics"
+	"fmt"
+	"time"
+)
+
+// processedUpdate marks a Telegram update as handled. Expires is meant to be used by a Datastore TTL policy,
+// the entities past it are deleted by Datastore.
+type processedUpdate struct {
+	ChatId    int64
+	UpdateId  int
+	Processed time.Time `datastore:",noindex"`
+	Expires   time.Time
+}
+
+// IsUpdateProcessed checks if the update was stored as processed and didn't expire yet.
+func (r *EventRepository) IsUpdateProcessed(ctx context.Context, chatId int64, updateId int) (_ bool, err error) {
+	defer metrics.ObserveRepositoryOp("is_update_processed", time.Now(), &err)
+	var update processedUpdate
+	err = r.dsClient.Get(ctx, processedUpdateKey(chatId, updateId), &update)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return update.Expires.After(time.Now()), nil
+}
+
+// MarkUpdateProcessed stores the update as processed for the ttl.
+func (r *EventRepository) MarkUpdateProcessed(ctx context.Context, chatId int64, updateId int, ttl time.Duration) (err error) {
+	defer metrics.ObserveRepositoryOp("mark_update_processed", time.Now(), &err)
+	now := time.Now()
+	_, err = r.dsClient.Put(ctx, processedUpdateKey(chatId, updateId), &processedUpdate{
+		ChatId:    chatId,
+		UpdateId:  updateId,
+		Processed: now,
+		Expires:   now.Add(ttl),
+	})
+	return err
+}
+
+func processedUpdateKey(chatId int64, updateId int) *datastore.Key {
+	return datastore.NameKey("ProcessedUpdate", fmt.Sprintf("%d-%d", chatId, updateId), nil)
+}
+
+// appliedRequest marks a request key as applied to the chat for the changes made outside events, e.g. to the
+// settings, the roles or the template. Like processedUpdate it expires.
+type appliedRequest struct {
+	ChatId     int64
+	RequestKey string    `datastore:",noindex"`
+	Applied    time.Time `datastore:",noindex"`
+	Expires    time.Time
+}
+
+// MarkRequestApplied stores the request key as applied to the chat for the ttl, in the transaction of the context
+// if there is one. It returns false if the key was already applied and didn't expire yet.
+func (r *EventRepository) MarkRequestApplied(ctx context.Context, chatId int64, requestKey string, ttl time.Duration) (_ bool, err error) {
+	defer metrics.ObserveRepositoryOp("mark_request_applied", time.Now(), &err)
+	key := appliedRequestKey(chatId, requestKey)
+	now := time.Now()
+	var applied appliedRequest
+	err = r.get(ctx, key, &applied)
+	if err == nil && applied.Expires.After(now) {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+		return false, err
+	}
+	err = r.put(ctx, key, &appliedRequest{
+		ChatId:     chatId,
+		RequestKey: requestKey,
+		Applied:    now,
+		Expires:    now.Add(ttl),
+	})
+	return err == nil, err
+}
+
+func appliedRequestKey(chatId int64, requestKey string) *datastore.Key {
+	return datastore.NameKey("AppliedRequest", fmt.Sprintf("%d-%s", chatId, requestKey), nil)
+}
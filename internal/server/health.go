@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+const checkTimeout = 3 * time.Second
+
+// Version is the application version, set at build time with
+// -ldflags "-X event-gorganizer/internal/server.Version=...".
+var Version = "dev"
+
+// Check reports whether a dependency is usable, a nil error means it is.
+type Check func(ctx context.Context) error
+
+type checksResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type versionResponse struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	BuildTime string `json:"buildTime,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// RegisterHealthHandlers adds /healthz, /readyz and /version endpoints to the mux.
+// /healthz answers as long as the process serves requests, /readyz runs the readiness checks.
+func RegisterHealthHandlers(mux *http.ServeMux, readinessChecks map[string]Check) {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, checksResponse{Status: "ok"})
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		defer cancel()
+		status, resp := runChecks(ctx, readinessChecks)
+		writeJson(w, status, resp)
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, getVersion())
+	})
+}
+
+func runChecks(ctx context.Context, checks map[string]Check) (int, checksResponse) {
+	status := http.StatusOK
+	resp := checksResponse{Status: "ok", Checks: make(map[string]string, len(checks))}
+	for name, check := range checks {
+		if err := check(ctx); err != nil {
+			log.Warn().Msgf("Readiness check %s failed: %s.", name, err)
+			status = http.StatusServiceUnavailable
+			resp.Status = "unavailable"
+			resp.Checks[name] = err.Error()
+		} else {
+			resp.Checks[name] = "ok"
+		}
+	}
+	return status, resp
+}
+
+func getVersion() versionResponse {
+	v := versionResponse{Version: Version}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+	v.GoVersion = info.GoVersion
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			v.Revision = setting.Value
+		case "vcs.time":
+			v.BuildTime = setting.Value
+		case "vcs.modified":
+			v.Modified = setting.Value == "true"
+		}
+	}
+	return v
+}
+
+func writeJson(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error().Msgf("Failed to write the response: %s.", err)
+	}
+}
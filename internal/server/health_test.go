@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadyz_AllChecksPass(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterHealthHandlers(mux, map[string]Check{
+		"storage": func(ctx context.Context) error { return nil },
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok","checks":{"storage":"ok"}}`, rec.Body.String())
+}
+
+func TestReadyz_CheckFails(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterHealthHandlers(mux, map[string]Check{
+		"storage":  func(ctx context.Context) error { return nil },
+		"telegram": func(ctx context.Context) error { return errors.New("getMe failed") },
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"unavailable","checks":{"storage":"ok","telegram":"getMe failed"}}`, rec.Body.String())
+}
+
+func TestHealthz(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterHealthHandlers(mux, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
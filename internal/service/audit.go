@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/tracing"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// maxAuditEntries bounds the number of audit entries returned at once.
+const maxAuditEntries = 100
+
+// GetAuditLog returns the latest changes of the event and of the chat since the event was created, the newest first.
+func (s *EventService) GetAuditLog(ctx context.Context, event *model.Event, limit int) (_ []*model.AuditEntry, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.GetAuditLog", trace.WithAttributes(attribute.Int64("chat.id", event.ChatId)))
+	defer tracing.End(span, &err)
+	return s.repo.GetAuditEntries(ctx, event.ChatId, event.Id(), event.Created, min(limit, maxAuditEntries))
+}
+
+// saveWithAudit saves the changed event and records the change made by the actor, both or neither are stored.
+// The participants before the change are kept as a revision to undo it, nil if the change can't be undone.
+// The participants affected by the change are notified.
+func (s *EventService) saveWithAudit(ctx context.Context, event *model.Event, previous []*model.Participant, actor model.ChatUser, entry model.AuditEntry) error {
+	entry = newEventAuditEntry(ctx, event, actor, entry)
+	var revision *model.EventRevision
+	if previous != nil {
+		revision = &model.EventRevision{EventId: entry.EventId, Change: entry, Participants: previous}
+	}
+	if err := s.repo.SaveChange(ctx, event, revision, &entry); err != nil {
+		return err
+	}
+	observe(event)
+	if previous != nil {
+		queue(ctx, event.Notifications(previous, actor)...)
+	}
+	return nil
+}
+
+// audit records the change made by the actor, entries are only ever added. Changes of the chat
+// are recorded without an event.
+func (s *EventService) audit(ctx context.Context, actor model.ChatUser, entry model.AuditEntry) error {
+	entry = newAuditEntry(ctx, actor, entry)
+	return s.repo.SaveAuditEntry(ctx, &entry)
+}
+
+func newEventAuditEntry(ctx context.Context, event *model.Event, actor model.ChatUser, entry model.AuditEntry) model.AuditEntry {
+	entry.ChatId = event.ChatId
+	entry.EventId = event.Id()
+	return newAuditEntry(ctx, actor, entry)
+}
+
+func newAuditEntry(ctx context.Context, actor model.ChatUser, entry model.AuditEntry) model.AuditEntry {
+	entry.ActorId = actor.UserId
+	entry.ActorName = actor.Mention()
+	entry.RequestKey = requestKey(ctx)
+	entry.Time = time.Now()
+	return entry
+}
+
+func participantRef(p *model.Participant) string {
+	return fmt.Sprintf("#%d %s", p.Number, p.Name)
+}
+
+func inviterName(p *model.Participant) string {
+	if p.InvitedBy == nil {
+		return ""
+	}
+	return p.InvitedBy.Name
+}
+
+func paymentState(p *model.Participant) string {
+	if p.PaymentStatus.Paid {
+		return "paid"
+	}
+	return "unpaid"
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/model"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// AdminChecker tells if the user is an administrator of the chat, the bot asks Telegram.
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, chatId int64, userId int64) (bool, error)
+}
+
+// ErrPermissionDenied matches every PermissionDeniedError with errors.Is.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// PermissionDeniedError is returned when the policy doesn't allow the actor the operation.
+type PermissionDeniedError struct {
+	Operation model.Operation
+	// Participant is the participant the operation was denied on, nil for operations on the whole event.
+	Participant *model.Participant
+}
+
+func (e *PermissionDeniedError) Error() string {
+	return fmt.Sprintf("permission to %s denied", e.Operation)
+}
+
+func (e *PermissionDeniedError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// SetAdminChecker makes the admin rule work, without a checker nobody is an admin.
+func (s *EventService) SetAdminChecker(admins AdminChecker) {
+	s.admins = admins
+}
+
+// authorize returns a PermissionDeniedError unless the policy allows the actor the operation on the participant.
+func (s *EventService) authorize(ctx context.Context, chatId int64, actor model.ChatUser, op model.Operation, participant *model.Participant) error {
+	allowed, err := s.allows(ctx, chatId, actor, op, participant)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return &PermissionDeniedError{Operation: op, Participant: participant}
+	}
+	return nil
+}
+
+// allows checks the rules of the operation, removing follows the remove setting of the chat.
+func (s *EventService) allows(ctx context.Context, chatId int64, actor model.ChatUser, op model.Operation, participant *model.Participant) (bool, error) {
+	rules := s.policy[op]
+	if op == model.OpRemove {
+		settings, err := s.GetSettings(ctx, chatId)
+		if err != nil {
+			return false, err
+		}
+		rules = settings.RemoveOthers.Rules()
+	}
+	subject := model.Subject{
+		UserId: actor.UserId,
+		Role: func() (model.Role, error) {
+			return s.GetRole(ctx, chatId, actor)
+		},
+		IsAdmin: func() (bool, error) {
+			if s.admins == nil {
+				return false, nil
+			}
+			return s.admins.IsAdmin(ctx, chatId, actor.UserId)
+		},
+	}
+	return rules.Allow(op, subject, participant)
+}
+
+// policyFromConfig overrides the default rules with PERMISSION_<OPERATION>, e.g. PERMISSION_MARK_PAID=role,admin.
+// Removing is configured per chat with the remove setting.
+func policyFromConfig() model.Policy {
+	policy := model.DefaultPolicy()
+	for _, op := range model.Operations {
+		if op == model.OpRemove {
+			continue
+		}
+		value := viper.GetString("PERMISSION_" + strings.ToUpper(string(op)))
+		if value == "" {
+			continue
+		}
+		rules, err := model.ParseRules(value)
+		if err != nil {
+			log.Warn().Msgf("Ignoring the permission rules of %s: %s.", op, err)
+			continue
+		}
+		policy[op] = rules
+	}
+	return policy
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/model"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// fakeAdmins tells the users with the ids as the admins of every chat.
+type fakeAdmins map[int64]bool
+
+func (a fakeAdmins) IsAdmin(_ context.Context, _ int64, userId int64) (bool, error) {
+	return a[userId], nil
+}
+
+// newAuthorizationTest returns the service with an event of Alice, #1, and her guest, #2. Carol is a chat member
+// without a role, Dave is an admin and Trudy is a treasurer.
+func newAuthorizationTest() (*EventService, *fakeRepository, *model.Event) {
+	repo := newFakeRepository()
+	alice := newTestParticipant(1, "Alice")
+	event := newTestEvent(-1, alice, &model.Participant{Name: "Guest", InvitedBy: alice})
+	repo.events[event.ChatId] = []*model.Event{event}
+	repo.roles[event.ChatId] = []*model.ChatRole{{ChatId: event.ChatId, UserId: 5, Role: model.RoleTreasurer}}
+	s := newTestService(repo)
+	s.SetAdminChecker(fakeAdmins{4: true})
+	return s, repo, event
+}
+
+var (
+	alice = model.ChatUser{ChatId: -1, UserId: 1, Name: "Alice"}
+	carol = model.ChatUser{ChatId: -1, UserId: 3, Name: "Carol"}
+	dave  = model.ChatUser{ChatId: -1, UserId: 4, Name: "Dave"}
+	trudy = model.ChatUser{ChatId: -1, UserId: 5, Name: "Trudy"}
+)
+
+func TestPermissionDeniedError_Is(t *testing.T) {
+	err := fmt.Errorf("failed to remove: %w", &PermissionDeniedError{Operation: model.OpRemove})
+
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	assert.False(t, errors.Is(ErrParticipantNotFound, ErrPermissionDenied))
+}
+
+func TestEventService_RemoveByNumberDeniedToNonInviter(t *testing.T) {
+	s, repo, event := newAuthorizationTest()
+
+	_, err := s.RemoveParticipantByNumber(context.Background(), event.ChatId, 2, carol)
+	var denied *PermissionDeniedError
+	assert.ErrorAs(t, err, &denied)
+	assert.Equal(t, model.OpRemove, denied.Operation)
+	assert.Equal(t, "Guest", denied.Participant.Name)
+	assert.Len(t, event.Participants, 2, "Carol removed the guest of Alice")
+	assert.Empty(t, repo.audit)
+
+	removed, err := s.RemoveParticipantByNumber(context.Background(), event.ChatId, 2, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, "Guest", removed.Name)
+	assert.Len(t, event.Participants, 1)
+}
+
+func TestEventService_RemoveParticipantAllowsSelfAndAdmins(t *testing.T) {
+	s, _, event := newAuthorizationTest()
+	guest := event.FindParticipantByNumber(2)
+
+	_, err := s.RemoveParticipant(context.Background(), event.ChatId, guest, carol)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+
+	_, err = s.RemoveParticipant(context.Background(), event.ChatId, guest, dave)
+	assert.NoError(t, err, "Admins remove anyone")
+
+	_, err = s.RemoveParticipant(context.Background(), event.ChatId, newTestParticipant(1, "Alice"), alice)
+	assert.NoError(t, err, "Everyone removes themselves")
+	assert.Empty(t, event.Participants)
+}
+
+func TestEventService_MarkPaidAuthorization(t *testing.T) {
+	s, _, event := newAuthorizationTest()
+	guest := event.FindParticipantByNumber(2)
+
+	_, err := s.MarkPaid(context.Background(), event.ChatId, guest, carol)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	assert.False(t, guest.PaymentStatus.Paid)
+
+	paid, err := s.MarkPaid(context.Background(), event.ChatId, guest, trudy)
+	assert.NoError(t, err, "Treasurers mark anyone paid")
+	assert.True(t, paid.PaymentStatus.Paid)
+
+	paid, err = s.MarkPaid(context.Background(), event.ChatId, newTestParticipant(1, "Alice"), alice)
+	assert.NoError(t, err)
+	assert.True(t, paid.PaymentStatus.Paid)
+}
+
+func TestEventService_RenameAuthorization(t *testing.T) {
+	s, _, event := newAuthorizationTest()
+
+	_, err := s.RenameParticipant(context.Background(), event.ChatId, 2, "Bob", carol)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	assert.Equal(t, "Guest", event.FindParticipantByNumber(2).Name)
+
+	_, err = s.RenameParticipant(context.Background(), event.ChatId, 2, "Bob", alice)
+	assert.NoError(t, err, "Inviters rename their guests")
+
+	renamed, err := s.RenameParticipant(context.Background(), event.ChatId, 1, "Alice B.", dave)
+	assert.NoError(t, err, "Admins rename anyone")
+	assert.Equal(t, "Alice B.", renamed.Name)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/tracing"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Notifier sends the notifications to the users in private chats, the bot sends them in the language
+// of the preferences.
+type Notifier interface {
+	Notify(ctx context.Context, notification model.Notification, preferences *model.NotificationPreferences)
+}
+
+// SetNotifier makes the changes notify the users who opted in, without a notifier nothing is sent.
+func (s *EventService) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+type outboxCtxKey struct{}
+
+// outbox collects the notifications of a change until it's committed. A retried transaction queues
+// the same notifications again, the set keeps each once.
+type outbox map[model.Notification]bool
+
+// withOutbox makes the changes made with the context queue their notifications, send them with flush.
+func withOutbox(ctx context.Context) (context.Context, outbox) {
+	box := make(outbox)
+	return context.WithValue(ctx, outboxCtxKey{}, box), box
+}
+
+// queue adds the notifications to the outbox of the context, they are dropped without one.
+func queue(ctx context.Context, notifications ...model.Notification) {
+	box, ok := ctx.Value(outboxCtxKey{}).(outbox)
+	if !ok {
+		return
+	}
+	for _, notification := range notifications {
+		box[notification] = true
+	}
+}
+
+// flush sends the queued notifications unless the change failed.
+func (s *EventService) flush(ctx context.Context, box outbox, err *error) {
+	if *err != nil {
+		return
+	}
+	for notification := range box {
+		s.notify(ctx, notification)
+	}
+}
+
+// notify sends the notification if the user opted in to it, it returns whether it was sent.
+func (s *EventService) notify(ctx context.Context, notification model.Notification) bool {
+	if s.notifier == nil {
+		return false
+	}
+	preferences, err := s.GetNotificationPreferences(ctx, notification.UserId)
+	if err != nil {
+		log.Warn().Msgf("Failed to get the notification preferences of the user %d: %s.", notification.UserId, err)
+		return false
+	}
+	if !preferences.Allows(notification.Kind) {
+		return false
+	}
+	s.notifier.Notify(ctx, notification, preferences)
+	return true
+}
+
+// GetNotificationPreferences returns the preferences of the user, nothing is enabled for a user who never changed them.
+func (s *EventService) GetNotificationPreferences(ctx context.Context, userId int64) (_ *model.NotificationPreferences, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.GetNotificationPreferences")
+	defer tracing.End(span, &err)
+	preferences, err := s.repo.GetNotificationPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		preferences = &model.NotificationPreferences{UserId: userId}
+	}
+	return preferences, nil
+}
+
+// ToggleNotifications opts the user in to the topic or out of it, the language is the one notifications are sent in.
+func (s *EventService) ToggleNotifications(ctx context.Context, userId int64, topic model.NotificationTopic, language string) (_ *model.NotificationPreferences, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.ToggleNotifications", trace.WithAttributes(attribute.String("topic", string(topic))))
+	defer tracing.End(span, &err)
+	preferences, err := s.GetNotificationPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	preferences.Toggle(topic)
+	preferences.Language = language
+	if err = s.repo.SaveNotificationPreferences(ctx, preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// RemindUnpaid reminds the users who owe for the active event to pay now, it returns how many were reminded.
+// Only the users who opted in to payment reminders get them, they are also reminded at the hours of the
+// reminders setting, see TakeDueReminders.
+func (s *EventService) RemindUnpaid(ctx context.Context, chatId int64, actor model.ChatUser) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.RemindUnpaid", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	event, err := s.GetActiveEvent(ctx, chatId)
+	if err != nil {
+		return 0, err
+	}
+	if err = s.authorize(ctx, chatId, actor, model.OpMarkPaid, nil); err != nil {
+		return 0, err
+	}
+	reminded := 0
+	for _, reminder := range event.PaymentReminders() {
+		if s.notify(ctx, reminder) {
+			reminded++
+		}
+	}
+	return reminded, nil
+}
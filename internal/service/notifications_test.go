@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQueue_KeepsNotificationsOnce(t *testing.T) {
+	notification := model.Notification{UserId: 1, Kind: model.NotificationPromoted, Event: "Football"}
+	queue(context.Background(), notification)
+
+	ctx, box := withOutbox(context.Background())
+	queue(ctx, notification)
+	// a retried transaction queues the same notification again
+	queue(ctx, notification, model.Notification{UserId: 2, Kind: model.NotificationAdded})
+	assert.Len(t, box, 2)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/tracing"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// RenameParticipant changes the name of the participant with the number, ErrParticipantNotFound is returned if there
+// is no such participant.
+func (s *EventService) RenameParticipant(ctx context.Context, chatId int64, number int, name string, actor model.ChatUser) (_ *model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.RenameParticipant", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.Int("participant.number", number)))
+	defer tracing.End(span, &err)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.Participant, error) {
+			event, err := s.GetActiveEvent(ctx, chatId)
+			if err != nil {
+				return nil, err
+			}
+			if !event.MarkApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			p := event.FindParticipantByNumber(number)
+			if p == nil {
+				return nil, ErrParticipantNotFound
+			}
+			if err = s.authorize(ctx, chatId, actor, model.OpEdit, p); err != nil {
+				return nil, err
+			}
+			before, previous := p.Name, event.Snapshot()
+			renamed, err := event.RenameParticipant(number, name)
+			if err != nil {
+				return nil, err
+			}
+			if err = s.saveWithAudit(ctx, event, previous, actor, model.AuditEntry{Action: model.ActionRename, Target: participantRef(renamed), Before: before, After: renamed.Name}); err != nil {
+				return nil, err
+			}
+			return renamed, nil
+		})
+}
+
+// MoveParticipant puts the participant with the number to the position of the participant with the other number,
+// ErrParticipantNotFound is returned if either number isn't in use.
+func (s *EventService) MoveParticipant(ctx context.Context, chatId int64, number int, to int, actor model.ChatUser) (_ *model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.MoveParticipant", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.Int("participant.number", number), attribute.Int("participant.to", to)))
+	defer tracing.End(span, &err)
+	ctx, box := withOutbox(ctx)
+	defer s.flush(ctx, box, &err)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.Participant, error) {
+			event, err := s.GetActiveEvent(ctx, chatId)
+			if err != nil {
+				return nil, err
+			}
+			if !event.MarkApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			p := event.FindParticipantByNumber(number)
+			if p == nil || event.FindParticipantByNumber(to) == nil {
+				return nil, ErrParticipantNotFound
+			}
+			if err = s.authorize(ctx, chatId, actor, model.OpEdit, p); err != nil {
+				return nil, err
+			}
+			// moving ahead pushes others back
+			if to < number {
+				if err = s.authorize(ctx, chatId, actor, model.OpEditOthers, nil); err != nil {
+					return nil, err
+				}
+			}
+			previous := event.Snapshot()
+			moved := event.MoveParticipant(number, to)
+			if moved == nil {
+				return nil, ErrParticipantNotFound
+			}
+			if err = s.saveWithAudit(ctx, event, previous, actor, model.AuditEntry{Action: model.ActionMove, Target: moved.Name, Before: fmt.Sprintf("#%d", number), After: fmt.Sprintf("#%d", moved.Number)}); err != nil {
+				return nil, err
+			}
+			return moved, nil
+		})
+}
+
+// SetInviter changes the inviter of the guest with the number, ErrParticipantNotFound is returned if there is
+// no such participant.
+func (s *EventService) SetInviter(ctx context.Context, chatId int64, number int, inviter *model.Participant, actor model.ChatUser) (_ *model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.SetInviter", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.Int("participant.number", number)))
+	defer tracing.End(span, &err)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.Participant, error) {
+			event, err := s.GetActiveEvent(ctx, chatId)
+			if err != nil {
+				return nil, err
+			}
+			if !event.MarkApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			p := event.FindParticipantByNumber(number)
+			if p == nil {
+				return nil, ErrParticipantNotFound
+			}
+			if err = s.authorize(ctx, chatId, actor, model.OpEdit, p); err != nil {
+				return nil, err
+			}
+			before, previous := inviterName(p), event.Snapshot()
+			guest, err := event.SetInviter(number, inviter)
+			if err != nil {
+				return nil, err
+			}
+			if err = s.saveWithAudit(ctx, event, previous, actor, model.AuditEntry{Action: model.ActionSetInviter, Target: participantRef(guest), Before: before, After: inviter.Name}); err != nil {
+				return nil, err
+			}
+			return guest, nil
+		})
+}
+
+// RemoveGuests removes the guests invited by the participant, e.g. after the inviter left.
+// ErrParticipantNotFound is returned if there are no such guests.
+func (s *EventService) RemoveGuests(ctx context.Context, chatId int64, inviter *model.Participant, actor model.ChatUser) (_ []*model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.RemoveGuests", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	ctx, box := withOutbox(ctx)
+	defer s.flush(ctx, box, &err)
+	var removed []*model.Participant
+	err = repository.ExecVoidTx(ctx, s.repo, false,
+		func(ctx context.Context) error {
+			event, err := s.GetActiveEvent(ctx, chatId)
+			if err != nil {
+				return err
+			}
+			if !event.MarkApplied(requestKey(ctx)) {
+				return ErrDuplicateRequest
+			}
+			guests := event.GuestsOf(inviter.Id())
+			if len(guests) == 0 {
+				return ErrParticipantNotFound
+			}
+			for _, guest := range guests {
+				if err = s.authorize(ctx, chatId, actor, model.OpRemove, guest); err != nil {
+					return err
+				}
+			}
+			if err = s.checkRegistration(ctx, chatId, actor, event, false); err != nil {
+				return err
+			}
+			previous := event.Snapshot()
+			refs := make([]string, 0, len(guests))
+			for _, guest := range guests {
+				event.RemoveParticipantByNumber(guest.Number)
+				refs = append(refs, participantRef(guest))
+			}
+			removed = guests
+			return s.saveWithAudit(ctx, event, previous, actor, model.AuditEntry{Action: model.ActionRemoveGuests, Target: inviter.Name, Before: strings.Join(refs, ", ")})
+		})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
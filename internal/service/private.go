@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/tracing"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// balanceEvents is how many of the latest events of each group the balance of the user is counted over.
+const balanceEvents = 5
+
+// Dashboard returns the upcoming events of the groups the user was seen in, ordered by the kickoff, the events
+// without a kickoff last. The balance is what the user owes for the latest events of the groups, also after
+// their kickoff. The groups whose events fail to load are left out.
+func (s *EventService) Dashboard(ctx context.Context, userId int64) (_ []model.GroupEvent, _ model.Balance, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.Dashboard")
+	defer tracing.End(span, &err)
+	chats, err := s.repo.GetChatsOfUser(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	var upcoming []model.GroupEvent
+	balance := model.Balance{}
+	for _, chat := range chats {
+		// private chats have positive ids
+		if chat.ChatId >= 0 {
+			continue
+		}
+		events, err := s.repo.GetEvents(ctx, chat.ChatId, balanceEvents)
+		if err != nil {
+			log.Warn().Msgf("Failed to get the events of the chat %d for the user %d: %s.", chat.ChatId, userId, err)
+			continue
+		}
+		for _, event := range events {
+			balance.Add(event, userId)
+		}
+		// the older events were finished when the next one was created
+		if len(events) > 0 {
+			events[0].Refresh(now)
+			if events[0].IsUpcoming(now) {
+				upcoming = append(upcoming, model.GroupEvent{ChatTitle: chat.ChatTitle, Event: events[0]})
+			}
+		}
+	}
+	model.SortByKickoff(upcoming)
+	return upcoming, balance, nil
+}
+
+// SelectChat makes the commands the user sends in the private chat change the events of the group.
+// The user is remembered in the group, so its events show up in the private chat.
+func (s *EventService) SelectChat(ctx context.Context, user model.ChatUser) (err error) {
+	ctx, span := tracer.Start(ctx, "EventService.SelectChat", trace.WithAttributes(attribute.Int64("chat.id", user.ChatId)))
+	defer tracing.End(span, &err)
+	if err = s.RecordChatUser(ctx, user); err != nil {
+		return err
+	}
+	return s.repo.SavePrivateChat(ctx, &model.PrivateChat{UserId: user.UserId, ChatId: user.ChatId, Selected: time.Now()})
+}
+
+// SelectedChat returns the group selected in the private chat of the user, 0 if there is none.
+func (s *EventService) SelectedChat(ctx context.Context, userId int64) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.SelectedChat")
+	defer tracing.End(span, &err)
+	chat, err := s.repo.GetPrivateChat(ctx, userId)
+	if err != nil || chat == nil {
+		return 0, err
+	}
+	return chat.ChatId, nil
+}
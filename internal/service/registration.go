@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/tracing"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// ErrRegistrationNotOpen is returned when signing up or leaving before registration opens.
+var ErrRegistrationNotOpen = errors.New("registration is not open yet")
+
+// ErrRegistrationClosed is returned when signing up or leaving after registration closed.
+var ErrRegistrationClosed = errors.New("registration is closed")
+
+// ErrInvalidSchedule is returned when registration would close before it opens.
+var ErrInvalidSchedule = errors.New("registration closes before it opens")
+
+// SetKickoff sets the start of the event, registration closes the hours of the closing setting before it.
+func (s *EventService) SetKickoff(ctx context.Context, chatId int64, startsAt time.Time, actor model.ChatUser) (*model.Event, error) {
+	return s.schedule(ctx, "EventService.SetKickoff", chatId, actor,
+		func(event *model.Event, settings *model.ChatSettings) model.AuditEntry {
+			loc := settings.Location()
+			before := model.FormatTime(event.StartsAt, loc)
+			event.ScheduleKickoff(startsAt, settings.ClosingHours, time.Now())
+			return model.AuditEntry{Action: model.ActionKickoff, Target: event.Title, Before: before, After: model.FormatTime(startsAt, loc)}
+		})
+}
+
+// OpenRegistrationAt makes registration open at the time, the opening is announced in the chat.
+func (s *EventService) OpenRegistrationAt(ctx context.Context, chatId int64, opensAt time.Time, actor model.ChatUser) (*model.Event, error) {
+	return s.schedule(ctx, "EventService.OpenRegistrationAt", chatId, actor,
+		func(event *model.Event, settings *model.ChatSettings) model.AuditEntry {
+			loc := settings.Location()
+			before := model.FormatTime(event.RegistrationOpensAt, loc)
+			event.RegistrationOpensAt = opensAt
+			return model.AuditEntry{Action: model.ActionOpens, Target: event.Title, Before: before, After: model.FormatTime(opensAt, loc)}
+		})
+}
+
+// CloseRegistration closes registration now, also if it wasn't open yet.
+func (s *EventService) CloseRegistration(ctx context.Context, chatId int64, actor model.ChatUser) (*model.Event, error) {
+	return s.schedule(ctx, "EventService.CloseRegistration", chatId, actor,
+		func(event *model.Event, settings *model.ChatSettings) model.AuditEntry {
+			event.RegistrationOpensAt = time.Time{}
+			event.RegistrationClosesAt = time.Now()
+			return model.AuditEntry{Action: model.ActionCloseSignup, Target: event.Title}
+		})
+}
+
+// ReopenRegistration opens registration now and lifts the closing time, the kickoff stays.
+func (s *EventService) ReopenRegistration(ctx context.Context, chatId int64, actor model.ChatUser) (*model.Event, error) {
+	return s.schedule(ctx, "EventService.ReopenRegistration", chatId, actor,
+		func(event *model.Event, settings *model.ChatSettings) model.AuditEntry {
+			event.ReopenRegistration()
+			return model.AuditEntry{Action: model.ActionReopenSignup, Target: event.Title}
+		})
+}
+
+// TakeDueOpenings returns the active events whose registration opened by the time and wasn't announced yet.
+// Each announcement is claimed and deleted in a transaction, so each opening is announced once also by
+// concurrent callers, the announcements failing to be claimed are left for the next call.
+func (s *EventService) TakeDueOpenings(ctx context.Context, now time.Time) (_ []*model.Event, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.TakeDueOpenings")
+	defer tracing.End(span, &err)
+	announcements, err := s.repo.GetDueAnnouncements(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	var events []*model.Event
+	for _, due := range announcements {
+		event, err := repository.ExecTx(ctx, s.repo, false,
+			func(ctx context.Context) (*model.Event, error) {
+				return s.claimAnnouncement(ctx, due.EventId, now)
+			})
+		if err != nil {
+			log.Warn().Msgf("Failed to take the announcement of the event %s: %s.", due.EventId, err)
+			continue
+		}
+		if event != nil {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// claimAnnouncement deletes the announcement of the event if it's still due by the time, it returns the event
+// to announce, nil if the announcement was taken already or the event was closed or its opening changed.
+func (s *EventService) claimAnnouncement(ctx context.Context, eventId string, now time.Time) (*model.Event, error) {
+	announcement, err := s.repo.GetAnnouncement(ctx, eventId)
+	if err != nil || announcement == nil || announcement.Due.After(now) {
+		return nil, err
+	}
+	if err = s.repo.DeleteAnnouncement(ctx, eventId); err != nil {
+		return nil, err
+	}
+	event, err := s.repo.GetActiveEvent(ctx, announcement.ChatId)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil || event.Id() != eventId || !event.RegistrationOpensAt.Equal(announcement.Due) {
+		return nil, nil
+	}
+	return event, nil
+}
+
+// checkRegistration returns ErrRegistrationNotOpen or ErrRegistrationClosed outside the registration window
+// of the participant unless the actor may schedule the event. Nobody signs up for or leaves an event which is
+// cancelled or already started, an error matching ErrEventClosed is returned then.
+func (s *EventService) checkRegistration(ctx context.Context, chatId int64, actor model.ChatUser, event *model.Event, priority bool) error {
+	switch {
+	case event.Status == model.StatusCancelled:
+		return &EventCancelledError{Event: event}
+	case !event.IsActive():
+		return &EventCompletedError{Event: event}
+	}
+	now := time.Now()
+	if event.RegistrationOpenFor(priority, now) {
+		return nil
+	}
+	allowed, err := s.allows(ctx, chatId, actor, model.OpSchedule, nil)
+	if err != nil || allowed {
+		return err
+	}
+	if event.RegistrationUpcoming(now) {
+		return ErrRegistrationNotOpen
+	}
+	return ErrRegistrationClosed
+}
+
+// hasPriority checks if the participant has the member role in the chat, guests have no priority.
+func (s *EventService) hasPriority(ctx context.Context, chatId int64, participant *model.Participant) (bool, error) {
+	if participant.TelegramId == nil || participant.InvitedBy != nil {
+		return false, nil
+	}
+	role, err := s.GetRole(ctx, chatId, model.ChatUser{ChatId: chatId, UserId: *participant.TelegramId, Username: participant.Username})
+	if err != nil {
+		return false, err
+	}
+	return role.HasPriority(), nil
+}
+
+// schedule applies the change to the times of the active event and keeps the announcement of the opening and
+// the reminders in sync.
+func (s *EventService) schedule(ctx context.Context, name string, chatId int64, actor model.ChatUser, change func(*model.Event, *model.ChatSettings) model.AuditEntry) (_ *model.Event, err error) {
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	ctx, box := withOutbox(ctx)
+	defer s.flush(ctx, box, &err)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.Event, error) {
+			event, err := s.GetActiveEvent(ctx, chatId)
+			if err != nil {
+				return nil, err
+			}
+			if !event.MarkApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			if err = s.authorize(ctx, chatId, actor, model.OpSchedule, nil); err != nil {
+				return nil, err
+			}
+			settings, err := s.GetSettings(ctx, chatId)
+			if err != nil {
+				return nil, err
+			}
+			startsAt := event.StartsAt
+			entry := change(event, settings)
+			event.Refresh(time.Now())
+			// participants are told about moving the kickoff, not about setting it unless the event is rescheduled
+			if !startsAt.Equal(event.StartsAt) && (!startsAt.IsZero() || entry.Action == model.ActionReschedule) {
+				kickoff := model.FormatTime(event.StartsAt, settings.Location())
+				queue(ctx, event.NotifyParticipants(model.NotificationRescheduled, actor, kickoff)...)
+			}
+			if !event.RegistrationOpensAt.IsZero() && !event.RegistrationClosesAt.IsZero() &&
+				!event.RegistrationOpensAt.Before(event.RegistrationClosesAt) {
+				return nil, ErrInvalidSchedule
+			}
+			if err = s.saveWithAudit(ctx, event, nil, actor, entry); err != nil {
+				return nil, err
+			}
+			if err = s.syncReminder(ctx, event, settings); err != nil {
+				return nil, err
+			}
+			if event.RegistrationUpcoming(time.Now()) {
+				err = s.repo.SaveAnnouncement(ctx, &model.Announcement{ChatId: chatId, EventId: event.Id(), Due: event.RegistrationOpensAt})
+			} else {
+				err = s.repo.DeleteAnnouncement(ctx, event.Id())
+			}
+			if err != nil {
+				return nil, err
+			}
+			return event, nil
+		})
+}
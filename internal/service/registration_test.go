@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEventService_CompletedEventRefusesChanges(t *testing.T) {
+	repo := newFakeRepository()
+	chatId := int64(-1)
+	event := newTestEvent(chatId, newTestParticipant(1, "Alice"))
+	event.StartsAt = time.Now().Add(-time.Hour)
+	repo.events[chatId] = []*model.Event{event}
+	s := newTestService(repo)
+
+	bob := model.ChatUser{ChatId: chatId, UserId: 2, Name: "Bob"}
+	_, err := s.AddNewParticipant(context.Background(), chatId, newTestParticipant(2, "Bob"), bob)
+	var completed *EventCompletedError
+	assert.ErrorAs(t, err, &completed)
+	assert.ErrorIs(t, err, ErrEventClosed)
+
+	alice := model.ChatUser{ChatId: chatId, UserId: 1, Name: "Alice"}
+	_, err = s.RemoveParticipant(context.Background(), chatId, newTestParticipant(1, "Alice"), alice)
+	assert.ErrorIs(t, err, ErrEventClosed)
+	assert.Len(t, event.Participants, 1)
+}
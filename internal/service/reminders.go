@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/tracing"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// syncReminder keeps the next reminder of the event in line with its kickoff and the reminders setting.
+func (s *EventService) syncReminder(ctx context.Context, event *model.Event, settings *model.ChatSettings) error {
+	reminder := event.NextReminder(settings.ReminderHours, time.Now())
+	if reminder == nil || !event.IsActive() {
+		return s.repo.DeleteReminder(ctx, event.Id())
+	}
+	return s.repo.SaveReminder(ctx, reminder)
+}
+
+// TakeDueReminders returns the active events whose kickoff is due to be reminded of by the time, the next
+// reminder of each replaces the sent one. The users who owe for the events are reminded to pay if they opted in.
+// Each reminder is claimed in a transaction, so it's taken once also by concurrent callers, the reminders
+// failing to be claimed are left for the next call.
+func (s *EventService) TakeDueReminders(ctx context.Context, now time.Time) (_ []*model.Event, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.TakeDueReminders")
+	defer tracing.End(span, &err)
+	reminders, err := s.repo.GetDueReminders(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	var events []*model.Event
+	for _, due := range reminders {
+		event, err := repository.ExecTx(ctx, s.repo, false,
+			func(ctx context.Context) (*model.Event, error) {
+				return s.claimReminder(ctx, due.EventId, now)
+			})
+		if err != nil {
+			log.Warn().Msgf("Failed to take the reminder of the event %s: %s.", due.EventId, err)
+			continue
+		}
+		if event == nil {
+			continue
+		}
+		for _, payment := range event.PaymentReminders() {
+			s.notify(ctx, payment)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// claimReminder deletes the reminder of the event if it's still due by the time and schedules the next one,
+// it returns the event to remind of, nil if the reminder was taken already or the event was closed or moved.
+func (s *EventService) claimReminder(ctx context.Context, eventId string, now time.Time) (*model.Event, error) {
+	reminder, err := s.repo.GetReminder(ctx, eventId)
+	if err != nil || reminder == nil || reminder.Due.After(now) {
+		return nil, err
+	}
+	if err = s.repo.DeleteReminder(ctx, eventId); err != nil {
+		return nil, err
+	}
+	event, err := s.repo.GetActiveEvent(ctx, reminder.ChatId)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil || event.Id() != eventId || !event.StartsAt.Equal(reminder.StartsAt) {
+		return nil, nil
+	}
+	settings, err := s.GetSettings(ctx, event.ChatId)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.syncReminder(ctx, event, settings); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// remindActiveEvent applies the changed reminders setting to the active event of the chat if there is one.
+func (s *EventService) remindActiveEvent(ctx context.Context, settings *model.ChatSettings) error {
+	event, err := s.repo.GetActiveEvent(ctx, settings.ChatId)
+	if err != nil || event == nil {
+		return err
+	}
+	return s.syncReminder(ctx, event, settings)
+}
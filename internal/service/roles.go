@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// RecordChatUser remembers the user of the chat, so it can be found by the username later.
+func (s *EventService) RecordChatUser(ctx context.Context, user model.ChatUser) (err error) {
+	ctx, span := tracer.Start(ctx, "EventService.RecordChatUser", trace.WithAttributes(attribute.Int64("chat.id", user.ChatId)))
+	defer tracing.End(span, &err)
+	user.Username = model.NormalizeUsername(user.Username)
+	user.LastSeen = time.Now()
+	return s.repo.SaveChatUser(ctx, &user)
+}
+
+// FindChatUser returns the user of the chat with the username, nil if the user wasn't seen in the chat.
+func (s *EventService) FindChatUser(ctx context.Context, chatId int64, username string) (_ *model.ChatUser, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.FindChatUser", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	return s.repo.FindChatUserByUsername(ctx, chatId, model.NormalizeUsername(username))
+}
+
+func (s *EventService) GrantRole(ctx context.Context, chatId int64, user model.ChatUser, role model.Role, actor model.ChatUser) (_ *model.ChatRole, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.GrantRole", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.String("role", string(role))))
+	defer tracing.End(span, &err)
+	chatRole := &model.ChatRole{
+		ChatId:    chatId,
+		UserId:    user.UserId,
+		Username:  model.NormalizeUsername(user.Username),
+		Name:      user.Name,
+		Role:      role,
+		GrantedBy: actor.UserId,
+		Granted:   time.Now(),
+	}
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.ChatRole, error) {
+			if err := s.markChatApplied(ctx, chatId); err != nil {
+				return nil, err
+			}
+			before, err := s.findRole(ctx, chatId, user)
+			if err != nil {
+				return nil, err
+			}
+			if err = s.repo.SaveRole(ctx, chatRole); err != nil {
+				return nil, err
+			}
+			entry := model.AuditEntry{ChatId: chatId, Action: model.ActionGrant, Target: chatRole.Mention(), After: string(role)}
+			if before != nil {
+				entry.Before = string(before.Role)
+			}
+			if err = s.audit(ctx, actor, entry); err != nil {
+				return nil, err
+			}
+			return chatRole, nil
+		})
+}
+
+// RevokeRole removes the role of the user, it returns the revoked role or nil if the user had none.
+func (s *EventService) RevokeRole(ctx context.Context, chatId int64, user model.ChatUser, actor model.ChatUser) (_ *model.ChatRole, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.RevokeRole", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	user.Username = model.NormalizeUsername(user.Username)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.ChatRole, error) {
+			if err := s.markChatApplied(ctx, chatId); err != nil {
+				return nil, err
+			}
+			role, err := s.findRole(ctx, chatId, user)
+			if err != nil || role == nil {
+				return nil, err
+			}
+			if err = s.repo.DeleteRole(ctx, chatId, user); err != nil {
+				return nil, err
+			}
+			entry := model.AuditEntry{ChatId: chatId, Action: model.ActionRevoke, Target: role.Mention(), Before: string(role.Role)}
+			if err = s.audit(ctx, actor, entry); err != nil {
+				return nil, err
+			}
+			return role, nil
+		})
+}
+
+// GetRole returns the role of the user in the chat, an empty role if the user has none.
+func (s *EventService) GetRole(ctx context.Context, chatId int64, user model.ChatUser) (_ model.Role, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.GetRole", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	user.Username = model.NormalizeUsername(user.Username)
+	role, err := s.findRole(ctx, chatId, user)
+	if err != nil || role == nil {
+		return "", err
+	}
+	return role.Role, nil
+}
+
+func (s *EventService) GetRoles(ctx context.Context, chatId int64) (_ []*model.ChatRole, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.GetRoles", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	return s.repo.GetRoles(ctx, chatId)
+}
+
+func (s *EventService) findRole(ctx context.Context, chatId int64, user model.ChatUser) (*model.ChatRole, error) {
+	roles, err := s.repo.GetRoles(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if role.Matches(user) {
+			return role, nil
+		}
+	}
+	return nil, nil
+}
@@ -2,43 +2,209 @@ package service
 
 import (
 	"context"
+	"errors"
+	"event-gorganizer/internal/metrics"
 	"event-gorganizer/internal/model"
 	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/tracing"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"time"
 )
 
+var tracer = otel.Tracer("event-gorganizer/internal/service")
+
+const (
+	// processedUpdateTtl is how long processed updates are remembered, Telegram keeps undelivered updates for 24 hours.
+	processedUpdateTtl = 48 * time.Hour
+	// defaultUndoWindow is how long a change can be undone unless UNDO_WINDOW is set.
+	defaultUndoWindow = 15 * time.Minute
+)
+
+// ErrDuplicateRequest is returned by mutations when the request with the same key was already applied.
+var ErrDuplicateRequest = errors.New("request already applied")
+
+// ErrGuestsNotAllowed is returned when adding a guest to an event of a chat which doesn't allow guests.
+var ErrGuestsNotAllowed = errors.New("guests are not allowed")
+
+// ErrGuestLimit is returned when the inviter already invited as many guests as the event allows.
+var ErrGuestLimit = errors.New("guest limit reached")
+
+// ErrCapacityFull is returned when the event reached its capacity and the chat has no waitlist.
+var ErrCapacityFull = errors.New("event is full")
+
+// ErrNoActiveEvent is returned when the chat never had an event.
+var ErrNoActiveEvent = errors.New("no active event")
+
+// ErrEventClosed is matched by the errors returned when the last event of the chat was cancelled or already
+// started and no new one was created, see EventCancelledError and EventCompletedError.
+var ErrEventClosed = errors.New("event is closed")
+
+// EventCancelledError is returned when the last event of the chat was cancelled and no new one was created.
+// It matches ErrEventClosed with errors.Is.
+type EventCancelledError struct {
+	Event *model.Event
+}
+
+func (e *EventCancelledError) Error() string {
+	return fmt.Sprintf("event %s is cancelled", e.Event.Id())
+}
+
+func (e *EventCancelledError) Is(target error) bool {
+	return target == ErrEventClosed
+}
+
+// EventCompletedError is returned when signing up for or leaving the last event of the chat after it started.
+// It matches ErrEventClosed with errors.Is.
+type EventCompletedError struct {
+	Event *model.Event
+}
+
+func (e *EventCompletedError) Error() string {
+	return fmt.Sprintf("event %s is completed", e.Event.Id())
+}
+
+func (e *EventCompletedError) Is(target error) bool {
+	return target == ErrEventClosed
+}
+
+// ErrParticipantNotFound is returned when changing a participant who isn't in the event.
+var ErrParticipantNotFound = errors.New("participant not found")
+
+// ErrAlreadyRegistered is returned when adding a participant who is already in the event.
+var ErrAlreadyRegistered = errors.New("participant already registered")
+
+type requestKeyCtxKey struct{}
+
+// WithRequestKey makes the mutations called with the context idempotent, a mutation is applied once per key.
+func WithRequestKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, requestKeyCtxKey{}, key)
+}
+
+func requestKey(ctx context.Context) string {
+	key, _ := ctx.Value(requestKeyCtxKey{}).(string)
+	return key
+}
+
+// markChatApplied records the request key of the context as applied to the chat, for the changes made outside
+// events, events keep their own keys. ErrDuplicateRequest is returned if the key was applied already, it's to be
+// called in the transaction of the change.
+func (s *EventService) markChatApplied(ctx context.Context, chatId int64) error {
+	key := requestKey(ctx)
+	if key == "" {
+		return nil
+	}
+	applied, err := s.repo.MarkRequestApplied(ctx, chatId, key, processedUpdateTtl)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return ErrDuplicateRequest
+	}
+	return nil
+}
+
+// Repository stores the events and everything around them, see repository.EventRepository.
+type Repository interface {
+	repository.Transactor
+	GetActiveEvent(ctx context.Context, chatId int64) (*model.Event, error)
+	GetEvents(ctx context.Context, chatId int64, limit int) ([]*model.Event, error)
+	SaveChange(ctx context.Context, event *model.Event, revision *model.EventRevision, entry *model.AuditEntry) error
+	GetRevisions(ctx context.Context, eventId string) ([]*model.EventRevision, error)
+	GetAuditEntries(ctx context.Context, chatId int64, eventId string, since time.Time, limit int) ([]*model.AuditEntry, error)
+	SaveAuditEntry(ctx context.Context, entry *model.AuditEntry) error
+	GetSettings(ctx context.Context, chatId int64) (*model.ChatSettings, error)
+	SaveSettings(ctx context.Context, settings *model.ChatSettings) error
+	GetTemplate(ctx context.Context, chatId int64) (*model.ChatTemplate, error)
+	SaveTemplate(ctx context.Context, template *model.ChatTemplate) error
+	DeleteTemplate(ctx context.Context, chatId int64) error
+	GetRoles(ctx context.Context, chatId int64) ([]*model.ChatRole, error)
+	SaveRole(ctx context.Context, role *model.ChatRole) error
+	DeleteRole(ctx context.Context, chatId int64, user model.ChatUser) error
+	SaveChatUser(ctx context.Context, user *model.ChatUser) error
+	FindChatUserByUsername(ctx context.Context, chatId int64, username string) (*model.ChatUser, error)
+	GetChatsOfUser(ctx context.Context, userId int64) ([]*model.ChatUser, error)
+	GetPrivateChat(ctx context.Context, userId int64) (*model.PrivateChat, error)
+	SavePrivateChat(ctx context.Context, chat *model.PrivateChat) error
+	GetNotificationPreferences(ctx context.Context, userId int64) (*model.NotificationPreferences, error)
+	SaveNotificationPreferences(ctx context.Context, preferences *model.NotificationPreferences) error
+	GetDueReminders(ctx context.Context, now time.Time) ([]*model.Reminder, error)
+	GetReminder(ctx context.Context, eventId string) (*model.Reminder, error)
+	SaveReminder(ctx context.Context, reminder *model.Reminder) error
+	DeleteReminder(ctx context.Context, eventId string) error
+	GetDueAnnouncements(ctx context.Context, now time.Time) ([]*model.Announcement, error)
+	GetAnnouncement(ctx context.Context, eventId string) (*model.Announcement, error)
+	SaveAnnouncement(ctx context.Context, announcement *model.Announcement) error
+	DeleteAnnouncement(ctx context.Context, eventId string) error
+	IsUpdateProcessed(ctx context.Context, chatId int64, updateId int) (bool, error)
+	MarkUpdateProcessed(ctx context.Context, chatId int64, updateId int, ttl time.Duration) error
+	MarkRequestApplied(ctx context.Context, chatId int64, requestKey string, ttl time.Duration) (bool, error)
+}
+
 type EventService struct {
-	repo *repository.EventRepository
+	repo       Repository
+	admins     AdminChecker
+	notifier   Notifier
+	policy     model.Policy
+	undoWindow time.Duration
 }
 
-func NewService(repo *repository.EventRepository) *EventService {
+func NewService(repo Repository) *EventService {
+	undoWindow := viper.GetDuration("UNDO_WINDOW")
+	if undoWindow <= 0 {
+		undoWindow = defaultUndoWindow
+	}
 	return &EventService{
-		repo: repo,
+		repo:       repo,
+		policy:     policyFromConfig(),
+		undoWindow: undoWindow,
 	}
 }
 
-func (s *EventService) CreateNewEvent(ctx context.Context, chatId int64, creator *model.Participant, title string) (*model.Event, error) {
+func (s *EventService) CreateNewEvent(ctx context.Context, chatId int64, creator *model.Participant, title string, actor model.ChatUser) (_ *model.Event, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.CreateNewEvent", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
 	tx, err := repository.ExecTx(ctx, s.repo, false,
-		func() (*model.Event, error) {
-			prevEvent, err := s.repo.GetActiveEvent(ctx, chatId)
+		func(ctx context.Context) (*model.Event, error) {
+			if err := s.authorize(ctx, chatId, actor, model.OpCreateEvent, nil); err != nil {
+				return nil, err
+			}
+			settings, err := s.GetSettings(ctx, chatId)
 			if err != nil {
 				return nil, err
 			}
-			prevEvent.Active = false
-			_, err = s.repo.Save(ctx, prevEvent)
+			prevEvent, err := s.repo.GetActiveEvent(ctx, chatId)
 			if err != nil {
 				return nil, err
 			}
+			if prevEvent != nil && prevEvent.IsApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			if prevEvent != nil {
+				prevEvent.Finish(time.Now())
+				if err = s.saveWithAudit(ctx, prevEvent, nil, actor, model.AuditEntry{Action: model.ActionClose, Target: prevEvent.Title}); err != nil {
+					return nil, err
+				}
+			}
 			newEvent := &model.Event{
-				ChatId:       chatId,
-				Creator:      creator,
-				Title:        title,
-				Created:      time.Now(),
-				Participants: make([]*model.Participant, 0),
-				Active:       true,
-			}
-			newEvent, err = s.repo.Save(ctx, newEvent)
-			if err != nil {
+				ChatId:           chatId,
+				Creator:          creator,
+				Title:            title,
+				Created:          time.Now(),
+				Participants:     make([]*model.Participant, 0),
+				Status:           model.StatusOpen,
+				Capacity:         settings.DefaultCapacity,
+				Price:            settings.DefaultPrice,
+				Currency:         settings.Currency,
+				EarlyAccessHours: settings.EarlyAccessHours,
+				Bump:             settings.Bump,
+				GuestLimit:       settings.GuestLimit,
+			}
+			newEvent.MarkApplied(requestKey(ctx))
+			if err = s.saveWithAudit(ctx, newEvent, nil, actor, model.AuditEntry{Action: model.ActionCreate, Target: title}); err != nil {
 				return nil, err
 			}
 			return newEvent, nil
@@ -46,55 +212,126 @@ func (s *EventService) CreateNewEvent(ctx context.Context, chatId int64, creator
 	return tx, err
 }
 
-func (s *EventService) GetActiveEvent(ctx context.Context, chatId int64) (*model.Event, error) {
+// GetActiveEvent returns the current event of the chat, the latest one, with the status as of now. It returns
+// an EventCancelledError if the latest event was cancelled and ErrNoActiveEvent if there was none.
+func (s *EventService) GetActiveEvent(ctx context.Context, chatId int64) (_ *model.Event, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.GetActiveEvent", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
 	tx, err := repository.ExecTx(ctx, s.repo, true,
-		func() (*model.Event, error) {
-			event, err := s.repo.GetActiveEvent(ctx, chatId)
+		func(ctx context.Context) (*model.Event, error) {
+			events, err := s.repo.GetEvents(ctx, chatId, 1)
 			if err != nil {
 				return nil, err
 			}
+			if len(events) == 0 {
+				return nil, ErrNoActiveEvent
+			}
+			event := events[0]
+			if event.Status == model.StatusCancelled {
+				return nil, &EventCancelledError{Event: event}
+			}
+			event.Refresh(time.Now())
+			observe(event)
 			return event, nil
 		})
 	return tx, err
 }
 
-func (s *EventService) AddNewParticipant(ctx context.Context, chatId int64, participant *model.Participant) (*model.Participant, error) {
+func (s *EventService) AddNewParticipant(ctx context.Context, chatId int64, participant *model.Participant, actor model.ChatUser) (_ *model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.AddNewParticipant", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	ctx, box := withOutbox(ctx)
+	defer s.flush(ctx, box, &err)
 	return repository.ExecTx(ctx, s.repo, false,
-		func() (*model.Participant, error) {
+		func(ctx context.Context) (*model.Participant, error) {
 			event, err := s.GetActiveEvent(ctx, chatId)
 			if err != nil {
 				return nil, err
 			}
-			if event.AddParticipant(participant) {
-				_, err = s.repo.Save(ctx, event)
-				if err != nil {
+			if !event.MarkApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			if participant.TelegramId != nil && *participant.TelegramId != actor.UserId {
+				if err = s.authorize(ctx, chatId, actor, model.OpAddOthers, participant); err != nil {
 					return nil, err
 				}
 			}
+			if event.FindParticipant(participant.Id()) != nil {
+				return nil, ErrAlreadyRegistered
+			}
+			if participant.Priority, err = s.hasPriority(ctx, chatId, participant); err != nil {
+				return nil, err
+			}
+			if err = s.checkRegistration(ctx, chatId, actor, event, participant.Priority); err != nil {
+				return nil, err
+			}
+			previous := event.Snapshot()
+			// the person may have been added as a guest before
+			if merged := event.MergeGuest(participant); merged != nil {
+				merged.Priority = participant.Priority
+				if err = s.saveWithAudit(ctx, event, previous, actor, model.AuditEntry{Action: model.ActionAdd, Target: participantRef(merged)}); err != nil {
+					return nil, err
+				}
+				return merged, nil
+			}
+			settings, err := s.GetSettings(ctx, chatId)
+			if err != nil {
+				return nil, err
+			}
+			if participant.InvitedBy != nil && !settings.GuestsAllowed {
+				return nil, ErrGuestsNotAllowed
+			}
+			if participant.InvitedBy != nil && !event.CanInvite(participant.InvitedBy.Id()) {
+				return nil, ErrGuestLimit
+			}
+			if !event.HasPlaceFor(participant) && !settings.Waitlist {
+				return nil, ErrCapacityFull
+			}
+			event.AddParticipant(participant)
+			if err = s.saveWithAudit(ctx, event, previous, actor, model.AuditEntry{Action: model.ActionAdd, Target: participantRef(participant), After: inviterName(participant)}); err != nil {
+				return nil, err
+			}
 			return participant, nil
 		})
 }
 
-func (s *EventService) RemoveParticipant(ctx context.Context, chatId int64, participant *model.Participant) (*model.Participant, error) {
+func (s *EventService) RemoveParticipant(ctx context.Context, chatId int64, participant *model.Participant, actor model.ChatUser) (_ *model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.RemoveParticipant", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	ctx, box := withOutbox(ctx)
+	defer s.flush(ctx, box, &err)
 	return repository.ExecTx(ctx, s.repo, false,
-		func() (*model.Participant, error) {
+		func(ctx context.Context) (*model.Participant, error) {
 			event, err := s.GetActiveEvent(ctx, chatId)
 			if err != nil {
 				return nil, err
 			}
+			if !event.MarkApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			found := event.FindParticipant(participant.Id())
+			if found == nil {
+				return nil, ErrParticipantNotFound
+			}
+			if err = s.authorize(ctx, chatId, actor, model.OpRemove, found); err != nil {
+				return nil, err
+			}
+			if err = s.checkRegistration(ctx, chatId, actor, event, found.Priority); err != nil {
+				return nil, err
+			}
+			previous := event.Snapshot()
 			removed := event.RemoveParticipant(participant.Id())
-			if removed != nil {
-				event, err = s.repo.Save(ctx, event)
-				if err != nil {
-					return nil, err
-				}
+			if err = s.saveWithAudit(ctx, event, previous, actor, model.AuditEntry{Action: model.ActionRemove, Target: participantRef(removed), Before: inviterName(removed)}); err != nil {
+				return nil, err
 			}
 			return removed, nil
 		})
 }
 
-func (s *EventService) FindParticipantByNumber(ctx context.Context, chatId int64, number int) (*model.Participant, error) {
-	return repository.ExecTx(ctx, s.repo, true, func() (*model.Participant, error) {
+func (s *EventService) FindParticipantByNumber(ctx context.Context, chatId int64, number int) (_ *model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.FindParticipantByNumber", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.Int("participant.number", number)))
+	defer tracing.End(span, &err)
+	return repository.ExecTx(ctx, s.repo, true, func(ctx context.Context) (*model.Participant, error) {
 		event, err := s.GetActiveEvent(ctx, chatId)
 		if err != nil {
 			return nil, err
@@ -103,46 +340,102 @@ func (s *EventService) FindParticipantByNumber(ctx context.Context, chatId int64
 	})
 }
 
-func (s *EventService) RemoveParticipantByNumber(ctx context.Context, chatId int64, idx int) (*model.Participant, error) {
+func (s *EventService) RemoveParticipantByNumber(ctx context.Context, chatId int64, idx int, actor model.ChatUser) (_ *model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.RemoveParticipantByNumber", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.Int("participant.number", idx)))
+	defer tracing.End(span, &err)
+	ctx, box := withOutbox(ctx)
+	defer s.flush(ctx, box, &err)
 	return repository.ExecTx(ctx, s.repo, false,
-		func() (*model.Participant, error) {
+		func(ctx context.Context) (*model.Participant, error) {
 			event, err := s.GetActiveEvent(ctx, chatId)
 			if err != nil {
 				return nil, err
 			}
+			if !event.MarkApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			found := event.FindParticipantByNumber(idx)
+			if found == nil {
+				return nil, ErrParticipantNotFound
+			}
+			if err = s.authorize(ctx, chatId, actor, model.OpRemove, found); err != nil {
+				return nil, err
+			}
+			if err = s.checkRegistration(ctx, chatId, actor, event, found.Priority); err != nil {
+				return nil, err
+			}
+			previous := event.Snapshot()
 			removed := event.RemoveParticipantByNumber(idx)
-			if removed != nil {
-				_, err := s.repo.Save(ctx, event)
-				if err != nil {
-					return nil, err
-				}
+			if err = s.saveWithAudit(ctx, event, previous, actor, model.AuditEntry{Action: model.ActionRemove, Target: participantRef(removed), Before: inviterName(removed)}); err != nil {
+				return nil, err
 			}
 			return removed, nil
 		})
 }
 
-func (s *EventService) MarkPaid(ctx context.Context, chatId int64, participant *model.Participant) error {
-	return repository.ExecVoidTx(ctx, s.repo, false,
-		func() error {
+// MarkPaid marks the participant as paid, ErrParticipantNotFound is returned if there is no such participant.
+func (s *EventService) MarkPaid(ctx context.Context, chatId int64, participant *model.Participant, actor model.ChatUser) (_ *model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.MarkPaid", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.Participant, error) {
 			event, err := s.GetActiveEvent(ctx, chatId)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			event.MarkPaid(participant.Id())
-			event, err = s.repo.Save(ctx, event)
-			return nil
+			return s.markPaid(ctx, event, event.FindParticipant(participant.Id()), actor)
 		})
 }
 
-func (s *EventService) MarkPaidByNumber(ctx context.Context, chatId int64, idx int) error {
-	return repository.ExecVoidTx(ctx, s.repo, false,
-		func() error {
+// MarkPaidByNumber marks the participant with the number as paid, ErrParticipantNotFound is returned if there is
+// no such participant.
+func (s *EventService) MarkPaidByNumber(ctx context.Context, chatId int64, idx int, actor model.ChatUser) (_ *model.Participant, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.MarkPaidByNumber", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.Int("participant.number", idx)))
+	defer tracing.End(span, &err)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.Participant, error) {
 			event, err := s.GetActiveEvent(ctx, chatId)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			event.MarkPaidByNumber(idx)
-			event, err = s.repo.Save(ctx, event)
-			return nil
+			return s.markPaid(ctx, event, event.FindParticipantByNumber(idx), actor)
 		})
 }
+
+func (s *EventService) markPaid(ctx context.Context, event *model.Event, participant *model.Participant, actor model.ChatUser) (*model.Participant, error) {
+	if !event.MarkApplied(requestKey(ctx)) {
+		return nil, ErrDuplicateRequest
+	}
+	if participant == nil {
+		return nil, ErrParticipantNotFound
+	}
+	if err := s.authorize(ctx, event.ChatId, actor, model.OpMarkPaid, participant); err != nil {
+		return nil, err
+	}
+	state, previous := paymentState(participant), event.Snapshot()
+	paid := event.MarkPaidByNumber(participant.Number)
+	entry := model.AuditEntry{Action: model.ActionPaid, Target: participantRef(paid), Before: state, After: paymentState(paid)}
+	if err := s.saveWithAudit(ctx, event, previous, actor, entry); err != nil {
+		return nil, err
+	}
+	return paid, nil
+}
+
+// IsUpdateProcessed checks if the update of the chat was already handled.
+func (s *EventService) IsUpdateProcessed(ctx context.Context, chatId int64, updateId int) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.IsUpdateProcessed", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.Int("update.id", updateId)))
+	defer tracing.End(span, &err)
+	return s.repo.IsUpdateProcessed(ctx, chatId, updateId)
+}
+
+// MarkUpdateProcessed records the update of the chat as handled, redeliveries of it are skipped then.
+func (s *EventService) MarkUpdateProcessed(ctx context.Context, chatId int64, updateId int) (err error) {
+	ctx, span := tracer.Start(ctx, "EventService.MarkUpdateProcessed", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.Int("update.id", updateId)))
+	defer tracing.End(span, &err)
+	return s.repo.MarkUpdateProcessed(ctx, chatId, updateId, processedUpdateTtl)
+}
+
+// observe updates the events metrics.
+func observe(event *model.Event) {
+	metrics.ObserveEvent(event.ChatId, event.IsActive(), len(event.Participants))
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// fakeRepository keeps the chats in memory, the methods the tests don't need panic.
+type fakeRepository struct {
+	Repository
+	events   map[int64][]*model.Event
+	settings map[int64]*model.ChatSettings
+	roles    map[int64][]*model.ChatRole
+	applied  map[string]bool
+	audit    []*model.AuditEntry
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		events:   map[int64][]*model.Event{},
+		settings: map[int64]*model.ChatSettings{},
+		roles:    map[int64][]*model.ChatRole{},
+		applied:  map[string]bool{},
+	}
+}
+
+func (r *fakeRepository) RunInTransaction(ctx context.Context, _ bool, f func(context.Context) error) error {
+	return f(ctx)
+}
+
+func (r *fakeRepository) GetEvents(_ context.Context, chatId int64, limit int) ([]*model.Event, error) {
+	events := r.events[chatId]
+	return events[:min(limit, len(events))], nil
+}
+
+func (r *fakeRepository) SaveChange(_ context.Context, event *model.Event, _ *model.EventRevision, entry *model.AuditEntry) error {
+	events := r.events[event.ChatId]
+	if len(events) == 0 || events[0].Id() != event.Id() {
+		r.events[event.ChatId] = append([]*model.Event{event}, events...)
+	}
+	r.audit = append(r.audit, entry)
+	return nil
+}
+
+func (r *fakeRepository) SaveAuditEntry(_ context.Context, entry *model.AuditEntry) error {
+	r.audit = append(r.audit, entry)
+	return nil
+}
+
+func (r *fakeRepository) GetSettings(_ context.Context, chatId int64) (*model.ChatSettings, error) {
+	return r.settings[chatId], nil
+}
+
+func (r *fakeRepository) SaveSettings(_ context.Context, settings *model.ChatSettings) error {
+	r.settings[settings.ChatId] = settings
+	return nil
+}
+
+func (r *fakeRepository) GetRoles(_ context.Context, chatId int64) ([]*model.ChatRole, error) {
+	return r.roles[chatId], nil
+}
+
+func (r *fakeRepository) MarkRequestApplied(_ context.Context, chatId int64, requestKey string, _ time.Duration) (bool, error) {
+	key := fmt.Sprintf("%d-%s", chatId, requestKey)
+	if r.applied[key] {
+		return false, nil
+	}
+	r.applied[key] = true
+	return true, nil
+}
+
+func newTestService(repo Repository) *EventService {
+	return &EventService{repo: repo, policy: model.DefaultPolicy(), undoWindow: defaultUndoWindow}
+}
+
+func newTestEvent(chatId int64, participants ...*model.Participant) *model.Event {
+	event := &model.Event{ChatId: chatId, Title: "Football", Created: time.Now(), Status: model.StatusOpen}
+	for _, participant := range participants {
+		event.AddParticipant(participant)
+	}
+	return event
+}
+
+func newTestParticipant(userId int64, name string) *model.Participant {
+	return &model.Participant{Name: name, TelegramId: &userId}
+}
+
+func TestEventService_RequestKeyAppliesEventChangeOnce(t *testing.T) {
+	repo := newFakeRepository()
+	chatId := int64(-1)
+	repo.events[chatId] = []*model.Event{newTestEvent(chatId)}
+	s := newTestService(repo)
+	ctx := WithRequestKey(context.Background(), "update-1")
+
+	alice := model.ChatUser{ChatId: chatId, UserId: 1, Name: "Alice"}
+	_, err := s.AddNewParticipant(ctx, chatId, newTestParticipant(1, "Alice"), alice)
+	assert.NoError(t, err)
+
+	bob := model.ChatUser{ChatId: chatId, UserId: 2, Name: "Bob"}
+	_, err = s.AddNewParticipant(ctx, chatId, newTestParticipant(2, "Bob"), bob)
+	assert.ErrorIs(t, err, ErrDuplicateRequest)
+	assert.Len(t, repo.events[chatId][0].Participants, 1, "The change was applied twice")
+
+	_, err = s.AddNewParticipant(WithRequestKey(context.Background(), "update-2"), chatId, newTestParticipant(2, "Bob"), bob)
+	assert.NoError(t, err)
+	assert.Len(t, repo.events[chatId][0].Participants, 2)
+}
+
+func TestEventService_RequestKeyAppliesChatChangeOnce(t *testing.T) {
+	repo := newFakeRepository()
+	chatId := int64(-1)
+	s := newTestService(repo)
+	ctx := WithRequestKey(context.Background(), "update-1")
+	admin := model.ChatUser{ChatId: chatId, UserId: 1, Name: "Admin"}
+
+	_, err := s.UpdateSetting(ctx, chatId, model.SettingCapacity, "10", admin)
+	assert.NoError(t, err)
+	_, err = s.UpdateSetting(ctx, chatId, model.SettingCapacity, "12", admin)
+	assert.ErrorIs(t, err, ErrDuplicateRequest)
+	assert.Equal(t, 10, repo.settings[chatId].DefaultCapacity)
+	assert.Len(t, repo.audit, 1)
+
+	_, err = s.UpdateSetting(context.Background(), chatId, model.SettingCapacity, "12", admin)
+	assert.NoError(t, err, "Changes without a request key are always applied")
+	assert.Equal(t, 12, repo.settings[chatId].DefaultCapacity)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetSettings returns the settings of the chat, the defaults if they were never changed.
+func (s *EventService) GetSettings(ctx context.Context, chatId int64) (_ *model.ChatSettings, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.GetSettings", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	settings, err := s.repo.GetSettings(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return model.DefaultChatSettings(chatId), nil
+	}
+	return settings, nil
+}
+
+// UpdateSetting changes one setting of the chat, an invalid value is reported with model.ErrInvalidSetting.
+func (s *EventService) UpdateSetting(ctx context.Context, chatId int64, key string, value string, actor model.ChatUser) (_ *model.ChatSettings, err error) {
+	key = model.NormalizeSettingKey(key)
+	ctx, span := tracer.Start(ctx, "EventService.UpdateSetting", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.String("setting", key)))
+	defer tracing.End(span, &err)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.ChatSettings, error) {
+			if err := s.markChatApplied(ctx, chatId); err != nil {
+				return nil, err
+			}
+			settings, err := s.GetSettings(ctx, chatId)
+			if err != nil {
+				return nil, err
+			}
+			before := settings.Get(key)
+			if err = settings.Set(key, value); err != nil {
+				return nil, err
+			}
+			if err = s.repo.SaveSettings(ctx, settings); err != nil {
+				return nil, err
+			}
+			if key == model.SettingReminders {
+				if err = s.remindActiveEvent(ctx, settings); err != nil {
+					return nil, err
+				}
+			}
+			entry := model.AuditEntry{ChatId: chatId, Action: model.ActionSetting, Target: key, Before: before, After: settings.Get(key)}
+			if err = s.audit(ctx, actor, entry); err != nil {
+				return nil, err
+			}
+			return settings, nil
+		})
+}
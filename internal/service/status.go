@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// maxHistoryEvents bounds the number of past events returned at once.
+const maxHistoryEvents = 20
+
+// CancelEvent calls the active event off, the participants are told the reason. The cancelled event stays
+// in the history, a new one is created with /new.
+func (s *EventService) CancelEvent(ctx context.Context, chatId int64, reason string, actor model.ChatUser) (_ *model.Event, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.CancelEvent", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	ctx, box := withOutbox(ctx)
+	defer s.flush(ctx, box, &err)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.Event, error) {
+			event, err := s.GetActiveEvent(ctx, chatId)
+			if err != nil {
+				return nil, err
+			}
+			if !event.MarkApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			if err = s.authorize(ctx, chatId, actor, model.OpCancel, nil); err != nil {
+				return nil, err
+			}
+			event.Cancel(reason)
+			entry := model.AuditEntry{Action: model.ActionCancel, Target: event.Title, After: reason}
+			if err = s.saveWithAudit(ctx, event, nil, actor, entry); err != nil {
+				return nil, err
+			}
+			// a cancelled event never opens nor starts
+			if err = s.repo.DeleteAnnouncement(ctx, event.Id()); err != nil {
+				return nil, err
+			}
+			if err = s.repo.DeleteReminder(ctx, event.Id()); err != nil {
+				return nil, err
+			}
+			queue(ctx, event.NotifyParticipants(model.NotificationCancelled, actor, reason)...)
+			return event, nil
+		})
+}
+
+// RescheduleEvent moves the kickoff of the active event, unlike SetKickoff the participants are told
+// even if the event had no kickoff yet.
+func (s *EventService) RescheduleEvent(ctx context.Context, chatId int64, startsAt time.Time, actor model.ChatUser) (*model.Event, error) {
+	return s.schedule(ctx, "EventService.RescheduleEvent", chatId, actor,
+		func(event *model.Event, settings *model.ChatSettings) model.AuditEntry {
+			loc := settings.Location()
+			before := model.FormatTime(event.StartsAt, loc)
+			event.ScheduleKickoff(startsAt, settings.ClosingHours, time.Now())
+			return model.AuditEntry{Action: model.ActionReschedule, Target: event.Title, Before: before, After: model.FormatTime(startsAt, loc)}
+		})
+}
+
+// History returns the latest events of the chat whatever their status, the newest first.
+func (s *EventService) History(ctx context.Context, chatId int64, limit int) (_ []*model.Event, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.History", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	events, err := s.repo.GetEvents(ctx, chatId, min(limit, maxHistoryEvents))
+	if err != nil {
+		return nil, err
+	}
+	// the older events were finished when the next one was created
+	if len(events) > 0 {
+		events[0].Refresh(time.Now())
+	}
+	return events, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// GetEventTemplate returns the custom event template of the chat, nil if the chat uses the default one.
+func (s *EventService) GetEventTemplate(ctx context.Context, chatId int64) (_ *model.ChatTemplate, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.GetEventTemplate", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	return s.repo.GetTemplate(ctx, chatId)
+}
+
+// SaveEventTemplate stores the custom event template of the chat, the template is expected to be validated.
+func (s *EventService) SaveEventTemplate(ctx context.Context, chatId int64, source string, actor model.ChatUser) (err error) {
+	ctx, span := tracer.Start(ctx, "EventService.SaveEventTemplate", trace.WithAttributes(attribute.Int64("chat.id", chatId), attribute.Int("template.size", len(source))))
+	defer tracing.End(span, &err)
+	return repository.ExecVoidTx(ctx, s.repo, false,
+		func(ctx context.Context) error {
+			if err := s.markChatApplied(ctx, chatId); err != nil {
+				return err
+			}
+			err := s.repo.SaveTemplate(ctx, &model.ChatTemplate{
+				ChatId:    chatId,
+				Source:    source,
+				UpdatedBy: actor.UserId,
+				Updated:   time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+			return s.audit(ctx, actor, model.AuditEntry{ChatId: chatId, Action: model.ActionTemplate})
+		})
+}
+
+// ResetEventTemplate makes the chat use the default event template again.
+func (s *EventService) ResetEventTemplate(ctx context.Context, chatId int64, actor model.ChatUser) (err error) {
+	ctx, span := tracer.Start(ctx, "EventService.ResetEventTemplate", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	return repository.ExecVoidTx(ctx, s.repo, false,
+		func(ctx context.Context) error {
+			if err := s.markChatApplied(ctx, chatId); err != nil {
+				return err
+			}
+			if err := s.repo.DeleteTemplate(ctx, chatId); err != nil {
+				return err
+			}
+			return s.audit(ctx, actor, model.AuditEntry{ChatId: chatId, Action: model.ActionResetTemplate})
+		})
+}
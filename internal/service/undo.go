@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"event-gorganizer/internal/model"
+	"event-gorganizer/internal/repository"
+	"event-gorganizer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// ErrNothingToUndo is returned when the event has no change of the actor to undo.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrUndoExpired is returned when the change is older than the undo window.
+var ErrUndoExpired = errors.New("the change is too old to undo")
+
+// ErrUndoConflict is returned when someone else changed the event after the change, undoing it would revert theirs.
+var ErrUndoConflict = errors.New("the event was changed afterwards")
+
+// UndoWindow is how long a change can be undone.
+func (s *EventService) UndoWindow() time.Duration {
+	return s.undoWindow
+}
+
+// Undo reverts the latest change of the actor to the active event, the latest change of anyone if the actor may edit
+// others. Only the latest change is undone, so earlier ones can be undone one by one. It returns the undone change.
+func (s *EventService) Undo(ctx context.Context, chatId int64, actor model.ChatUser) (_ *model.AuditEntry, err error) {
+	ctx, span := tracer.Start(ctx, "EventService.Undo", trace.WithAttributes(attribute.Int64("chat.id", chatId)))
+	defer tracing.End(span, &err)
+	ctx, box := withOutbox(ctx)
+	defer s.flush(ctx, box, &err)
+	return repository.ExecTx(ctx, s.repo, false,
+		func(ctx context.Context) (*model.AuditEntry, error) {
+			event, err := s.GetActiveEvent(ctx, chatId)
+			if err != nil {
+				return nil, err
+			}
+			if !event.MarkApplied(requestKey(ctx)) {
+				return nil, ErrDuplicateRequest
+			}
+			anyone, err := s.allows(ctx, chatId, actor, model.OpEditOthers, nil)
+			if err != nil {
+				return nil, err
+			}
+			revisions, err := s.repo.GetRevisions(ctx, event.Id())
+			if err != nil {
+				return nil, err
+			}
+			revision, err := s.revisionToUndo(revisions, actor, anyone)
+			if err != nil {
+				return nil, err
+			}
+			before := event.Snapshot()
+			event.Restore(revision.Participants)
+			queue(ctx, event.Notifications(before, actor)...)
+			// the revision is marked undone along with the restored event
+			revision.Undone = true
+			change := revision.Change
+			entry := newEventAuditEntry(ctx, event, actor, model.AuditEntry{Action: model.ActionUndo, Target: change.Target, Before: change.Action})
+			if err = s.repo.SaveChange(ctx, event, revision, &entry); err != nil {
+				return nil, err
+			}
+			observe(event)
+			return &change, nil
+		})
+}
+
+// revisionToUndo picks the latest revision which wasn't undone yet, the revisions are expected newest first.
+func (s *EventService) revisionToUndo(revisions []*model.EventRevision, actor model.ChatUser, anyone bool) (*model.EventRevision, error) {
+	var latest *model.EventRevision
+	for _, revision := range revisions {
+		if revision.Undone {
+			continue
+		}
+		if latest == nil {
+			latest = revision
+		}
+		if !anyone && revision.Change.ActorId != actor.UserId {
+			continue
+		}
+		if revision != latest {
+			return nil, ErrUndoConflict
+		}
+		if time.Since(revision.Change.Time) > s.undoWindow {
+			return nil, ErrUndoExpired
+		}
+		return revision, nil
+	}
+	return nil, ErrNothingToUndo
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"event-gorganizer/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRevisionToUndo(t *testing.T) {
+	s := &EventService{undoWindow: 15 * time.Minute}
+	alice := model.ChatUser{UserId: 1}
+	bob := model.ChatUser{UserId: 2}
+	revision := func(actor model.ChatUser, age time.Duration, undone bool) *model.EventRevision {
+		return &model.EventRevision{Change: model.AuditEntry{ActorId: actor.UserId, Time: time.Now().Add(-age)}, Undone: undone}
+	}
+	latestOfAlice := revision(alice, time.Minute, false)
+	revisions := []*model.EventRevision{
+		revision(bob, 0, true),
+		latestOfAlice,
+		revision(alice, 2*time.Minute, false),
+		revision(bob, 3*time.Minute, false),
+	}
+
+	undone, err := s.revisionToUndo(revisions, alice, false)
+	assert.NoError(t, err)
+	assert.Same(t, latestOfAlice, undone, "The latest change of Alice is not undone")
+
+	_, err = s.revisionToUndo(revisions, bob, false)
+	assert.ErrorIs(t, err, ErrUndoConflict, "Bob undoes his change made before the change of Alice")
+
+	undone, err = s.revisionToUndo(revisions, bob, true)
+	assert.NoError(t, err)
+	assert.Same(t, latestOfAlice, undone, "Admins don't undo the latest change")
+
+	_, err = s.revisionToUndo([]*model.EventRevision{revision(alice, time.Hour, false)}, alice, false)
+	assert.ErrorIs(t, err, ErrUndoExpired)
+
+	_, err = s.revisionToUndo(revisions[:1], alice, false)
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
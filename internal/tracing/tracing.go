@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "event-gorganizer"
+
+// Exporters supported by Init.
+const (
+	ExporterNone   = "none"
+	ExporterOtlp   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Init installs the global tracer provider exporting spans with the given exporter.
+// The OTLP exporter is configured with the standard OTEL_EXPORTER_OTLP_* variables,
+// by default it sends spans to a local collector. The returned function flushes and stops the exporter.
+func Init(ctx context.Context, exporter string, version string) (func(ctx context.Context) error, error) {
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", ExporterNone:
+		log.Info().Msg("Tracing is disabled.")
+		return func(ctx context.Context) error { return nil }, nil
+	case ExporterOtlp:
+		spanExporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %s", exporter)
+	}
+	if err != nil {
+		log.Error().Msgf("Failed to create the %s traces exporter: %s.", exporter, err)
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	log.Info().Msgf("Exporting traces with %s exporter.", exporter)
+	return provider.Shutdown, nil
+}
+
+// End ends the span, marking it as failed if the error is set. It's meant to be deferred
+// with a pointer to the named error result.
+func End(span trace.Span, err *error) {
+	RecordError(span, *err)
+	span.End()
+}
+
+// RecordError marks the span as failed if err is not nil.
+func RecordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}